@@ -1,9 +1,12 @@
 package analyzer
 
 import (
+	structs "backend/Structs"
 	commands "backend/commands"
 	Disks "backend/commands/Disks"
 	Users "backend/commands/Users"
+	globals "backend/globals"
+	"backend/utils"
 	"errors"
 	"fmt"
 	"os"
@@ -13,75 +16,99 @@ import (
 )
 
 // mapCommands define un mapeo entre comandos y funciones correspondientes
-var mapCommands = map[string]func([]string) (string, error){ // Cambiamos a (string, error)
-	"mkdisk": func(args []string) (string, error) {
+// Cada comando recibe el usuario con el que se ejecuta, nil si no hay sesión
+var mapCommands = map[string]func([]string, *structs.User) (string, error){ // Cambiamos a (string, error)
+	"mkdisk": func(args []string, _ *structs.User) (string, error) {
 		result, err := Disks.ParserMkdisk(args)
 		return fmt.Sprintf("%v", result), err // Aseguramos que se devuelva un string
 	},
-	"rmdisk": func(args []string) (string, error) {
+	"rmdisk": func(args []string, _ *structs.User) (string, error) {
 		result, err := Disks.ParserRmdisk(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"fdisk": func(args []string) (string, error) {
+	"fdisk": func(args []string, _ *structs.User) (string, error) {
 		result, err := Disks.ParserFdisk(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mount": func(args []string) (string, error) {
+	"mount": func(args []string, _ *structs.User) (string, error) {
 		result, err := Disks.ParserMount(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mkfs": func(args []string) (string, error) {
+	"lsdisk": func(args []string, _ *structs.User) (string, error) {
+		result, err := Disks.ParserLsdisk(args)
+		return fmt.Sprintf("%v", result), err
+	},
+	"mounted": func(args []string, _ *structs.User) (string, error) {
+		result, err := Disks.ParserMounted(args)
+		return fmt.Sprintf("%v", result), err
+	},
+	"mkfs": func(args []string, _ *structs.User) (string, error) {
 		result, err := Disks.ParserMkfs(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"rep": func(args []string) (string, error) {
-		result, err := commands.ParserRep(args)
+	"fsck": func(args []string, _ *structs.User) (string, error) {
+		result, err := Disks.ParserFsck(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"login": func(args []string) (string, error) {
+	"rep": func(args []string, user *structs.User) (string, error) {
+		result, err := commands.ParserRep(args, user)
+		return fmt.Sprintf("%v", result), err
+	},
+	"login": func(args []string, _ *structs.User) (string, error) {
 		result, err := Users.ParserLogin(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"logout": func(args []string) (string, error) {
+	"logout": func(args []string, _ *structs.User) (string, error) {
 		result, err := Users.ParserLogout(args)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mkgrp": func(args []string) (string, error) {
-		result, err := Users.ParserMkgrp(args)
+	"mkgrp": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserMkgrp(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"rmgrp": func(args []string) (string, error) {
-		result, err := Users.ParserRmgrp(args)
+	"rmgrp": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserRmgrp(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mkusr": func(args []string) (string, error) {
-		result, err := Users.ParserMkusr(args)
+	"mkusr": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserMkusr(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"rmusr": func(args []string) (string, error) {
-		result, err := Users.ParserRmusr(args)
+	"rmusr": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserRmusr(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"chgrp": func(args []string) (string, error) {
-		result, err := Users.ParserChgrp(args)
+	"chgrp": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserChgrp(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mkfile": func(args []string) (string, error) {
-		result, err := commands.ParserMkfile(args)
+	"setadmin": func(args []string, user *structs.User) (string, error) {
+		result, err := Users.ParserSetadmin(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"mkdir": func(args []string) (string, error) {
-		result, err := commands.ParserMkdir(args)
+	"mkfile": func(args []string, user *structs.User) (string, error) {
+		result, err := commands.ParserMkfile(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
-	"cat": func(args []string) (string, error) {
-		result, err := commands.ParserCat(args)
+	"mkdir": func(args []string, user *structs.User) (string, error) {
+		result, err := commands.ParserMkdir(args, user)
+		return fmt.Sprintf("%v", result), err
+	},
+	"cat": func(args []string, user *structs.User) (string, error) {
+		result, err := commands.ParserCat(args, user)
 		return fmt.Sprintf("%v", result), err
 	},
 	"help": help,
 }
 
+// Analyzer ejecuta una línea con el usuario de la sesión activa
+// La sesión se lee una sola vez por solicitud, el bloqueo del disco y el comando usan la misma copia
 func Analyzer(input string) (string, error) {
+	return analyze(input, globals.CurrentUser())
+}
+
+// analyze ejecuta una línea con el usuario indicado, su la usa para ejecutar su línea como root
+func analyze(input string, user *structs.User) (string, error) {
 	// Verificar si es un comentario
 	if strings.HasPrefix(strings.TrimSpace(input), "#") {
 		// Retornamos el comentario sin procesarlo
@@ -99,24 +126,76 @@ func Analyzer(input string) (string, error) {
 	if !exists {
 		if tokens[0] == "clear" {
 			return clearTerminal()
+		} else if tokens[0] == "su" {
+			// su se resuelve aquí porque vuelve a invocar al analizador con la línea restante
+			return Users.ParserSu(tokens[1:], user, analyze)
 		} else if tokens[0] == "exit" {
 			os.Exit(0)
 		}
 		return "", fmt.Errorf("comando desconocido: %s", tokens[0])
 	}
 
+	// Bloquear el disco que usa el comando mientras se ejecuta, el servidor atiende solicitudes en paralelo
+	path, write, unlock := lockCommandDisk(tokens[0], tokens[1:], user)
+	defer unlock()
+
+	if path == "" {
+		// Ejecutar la función correspondiente
+		return cmdFunc(tokens[1:], user)
+	}
+
+	if !write {
+		// Los comandos de lectura usan el caché del disco mientras se ejecutan
+		tx, err := utils.BeginReadTransaction(path)
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback()
+
+		// Ejecutar la función correspondiente
+		return cmdFunc(tokens[1:], user)
+	}
+
+	// Las escrituras del comando se aplican todas juntas al terminar, o ninguna si el comando falla
+	tx, err := utils.BeginTransaction(path)
+	if err != nil {
+		return "", err
+	}
+
 	// Ejecutar la función correspondiente
-	return cmdFunc(tokens[1:])
+	result, err := cmdFunc(tokens[1:], user)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+
+	err = tx.Commit()
+	if errors.Is(err, utils.ErrAfterCommit) {
+		// El disco quedó actualizado, solo falló guardar el estado del servidor
+		return result + fmt.Sprintf("Advertencia: %v\n", err), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al guardar los cambios en el disco: %w", err)
+	}
+	return result, nil
 }
 
-func help(args []string) (string, error) {
+func help(args []string, _ *structs.User) (string, error) {
+	// help rep lista los reportes registrados
+	if len(args) > 0 && args[0] == "rep" {
+		return commands.HelpRep(), nil
+	}
+
 	helpMessage := `
 Comandos disponibles:
 - mkdisk: Crea un nuevo disco. Ejemplo: mkdisk -size=100 -unit=M -fit=FF -path="/home/user/disco.mia"
 - rmdisk: Elimina un disco existente. Ejemplo: rmdisk -path="/home/user/disco.mia"
 - fdisk: Maneja las particiones del disco. Ejemplo: fdisk -size=50 -unit=M -path="/home/user/disco.mia" -type=P -name="Part1"
 - mount: Monta una partición. Ejemplo: mount -path="/home/user/disco.mia" -name="Part1"
-- mkfs: Formatea una partición. Ejemplo: mkfs -id=vd1 -type=full
+- lsdisk: Lista los discos registrados con su tamaño, firma y particiones. Ejemplo: lsdisk
+- mounted: Lista las particiones montadas con su disco, nombre y sistema de archivos. Ejemplo: mounted
+- mkfs: Formatea una partición (fast o full), opcionalmente con tamaño de bloque y bloques por inodo. Ejemplo: mkfs -id=vd1 -type=full -bs=128 -inoderatio=2
+- fsck: Verifica la consistencia del sistema de archivos, -repair corrige los problemas. Ejemplo: fsck -id=vd1 -repair
 - login: Inicia sesión en el sistema. Ejemplo: login -user=admin -pass=1234 -id=vd1
 - logout: Cierra la sesión actual. Ejemplo: logout
 - mkgrp: Crea un nuevo grupo. Ejemplo: mkgrp -name=users
@@ -124,10 +203,13 @@ Comandos disponibles:
 - mkusr: Crea un nuevo usuario. Ejemplo: mkusr -user=user1 -pass=user -grp=users
 - rmusr: Elimina un usuario existente. Ejemplo: rmusr -user=user1
 - chgrp: Cambia el grupo de un usuario. Ejemplo: chgrp -user=user1 -grp=users
+- setadmin: Define el grupo con privilegios de administración (solo root). Ejemplo: setadmin -grp=admins
+- su: Ejecuta una línea como root tras validar su contraseña. Ejemplo: su -pass=123 mkgrp -name=users
+- cat: Muestra el contenido de archivos, opcionalmente un rango de bytes. Ejemplo: cat -file1=/home/a.txt -offset=0 -length=64
 - rep: Genera reportes. Ejemplo: rep -id=vd1 -path="/home/user/disco.mia" -name=mbr
 - clear: Limpia la terminal.
 - exit: Sale del programa.
-- help: Muestra este mensaje de ayuda.
+- help: Muestra este mensaje de ayuda. Use "help rep" para ver los reportes disponibles.
 
 `
 	return helpMessage, nil
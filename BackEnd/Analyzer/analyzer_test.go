@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	structs "backend/Structs"
+	Users "backend/commands/Users"
+	globals "backend/globals"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestServer deja el estado de montajes en un directorio temporal y se mueve a él
+// Devuelve el directorio, donde las pruebas crean sus discos
+func newTestServer(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	stateFile := globals.MountStateFile
+	globals.MountStateFile = filepath.Join(dir, "mount_state.json")
+
+	t.Cleanup(func() {
+		globals.Logout()
+		for _, path := range globals.DiskPaths() {
+			if strings.HasPrefix(path, dir) {
+				globals.UnregisterDisk(path)
+			}
+		}
+		globals.MountStateFile = stateFile
+		os.Chdir(wd)
+	})
+	return dir
+}
+
+// run ejecuta una línea como lo hace el servidor y detiene la prueba si falla
+func run(t *testing.T, line string) string {
+	t.Helper()
+	output, err := Analyzer(line)
+	if err != nil {
+		t.Fatalf("%s: %v", line, err)
+	}
+	return output
+}
+
+// mountedIDs devuelve los IDs montados del disco
+func mountedIDs(disk string) []string {
+	var ids []string
+	for _, id := range globals.MountedIDs() {
+		if globals.GetMountedPath(id) == disk {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestMountExactPartition(t *testing.T) {
+	dir := newTestServer(t)
+	disk := filepath.Join(dir, "disco.mia")
+	run(t, "mkdisk -size=1 -unit=M -path="+disk)
+	run(t, "fdisk -size=100 -unit=K -path="+disk+" -name=Part1")
+	run(t, "fdisk -size=100 -unit=K -path="+disk+" -name=Part10")
+
+	// Part1 no se confunde con Part10, aunque un nombre contenga al otro
+	run(t, "mount -path="+disk+" -name=Part10")
+	run(t, "mount -path="+disk+" -name=Part1")
+	if ids := mountedIDs(disk); len(ids) != 2 {
+		t.Fatalf("particiones montadas = %q, se esperaban 2", ids)
+	}
+
+	// La misma partición con un path relativo ya está montada
+	for _, path := range []string{"disco.mia", "./disco.mia", disk} {
+		_, err := Analyzer("mount -path=" + path + " -name=Part1")
+		if err == nil || !strings.Contains(err.Error(), "ya está montada") {
+			t.Fatalf("mount -path=%s -name=Part1: error = %v, se esperaba que ya estuviera montada", path, err)
+		}
+	}
+	if ids := mountedIDs(disk); len(ids) != 2 {
+		t.Fatalf("particiones montadas = %q, se esperaban 2", ids)
+	}
+}
+
+// newTestPartition crea un disco con Part1 montada y formateada en dir y devuelve el disco y el ID de la partición
+func newTestPartition(t *testing.T, dir string, fs string) (string, string) {
+	t.Helper()
+	disk := filepath.Join(dir, "disco.mia")
+	run(t, "mkdisk -size=1 -unit=M -path="+disk)
+	run(t, "fdisk -size=500 -unit=K -path="+disk+" -name=Part1")
+	run(t, "mount -path="+disk+" -name=Part1")
+	id := mountedIDs(disk)[0]
+	run(t, "mkfs -id="+id+" -fs="+fs)
+	return disk, id
+}
+
+func TestAdminGroup(t *testing.T) {
+	dir := newTestServer(t)
+	disk, id := newTestPartition(t, dir, "2fs")
+
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=admins")
+	run(t, "mkusr -user=ana -pass=123 -grp=admins")
+	run(t, "setadmin -grp=admins")
+
+	// El grupo administrador no se puede eliminar mientras lo sea
+	_, err := Analyzer("rmgrp -name=admins")
+	if err == nil || !strings.Contains(err.Error(), "grupo administrador") {
+		t.Fatalf("rmgrp del grupo administrador: error = %v, se esperaba que se rechazara", err)
+	}
+	run(t, "logout")
+
+	run(t, "login -user=ana -pass=123 -id="+id)
+	run(t, "mkgrp -name=ventas")
+
+	// Un users.txt con el grupo eliminado, la sesión de ana todavía tiene el grupo guardado
+	file, err := os.OpenFile(disk, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	sb, _, _, err := globals.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usersInode structs.Inode
+	inodeOffset := int64(sb.S_inode_start + sb.S_inode_size)
+	if err := usersInode.Decode(file, inodeOffset); err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.UpdateEntityStateOrRemoveUsers(file, sb, &usersInode, "admins", "G", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := usersInode.Encode(file, inodeOffset); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Analyzer("mkgrp -name=compras")
+	if err == nil || !strings.Contains(err.Error(), "fue eliminado") {
+		t.Fatalf("mkgrp con el grupo administrador eliminado: error = %v, se esperaba que se rechazara", err)
+	}
+}
+
+func TestChgrpKeepsUsersFile(t *testing.T) {
+	dir := newTestServer(t)
+	_, id := newTestPartition(t, dir, "2fs")
+
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkgrp -name=ventas")
+	run(t, "mkusr -user=ana -pass=123 -grp=ventas")
+	run(t, "mkgrp -name=compras")
+	run(t, "chgrp -usr=ana -grp=compras")
+
+	// users.txt conserva todas las líneas y las siguientes entradas quedan en su propia línea
+	run(t, "mkgrp -name=bodega")
+	output := run(t, "cat -file1=/users.txt")
+	for _, line := range []string{"1,U,root,root,123", "2,G,ventas", "3,U,compras,ana,123", "4,G,bodega"} {
+		if !strings.Contains(output, line+"\n") {
+			t.Fatalf("users.txt no contiene %q:\n%s", line, output)
+		}
+	}
+	run(t, "rmusr -usr=ana")
+}
+
+func TestJournalingReport(t *testing.T) {
+	dir := newTestServer(t)
+	_, id := newTestPartition(t, dir, "3fs")
+
+	run(t, "login -user=root -pass=123 -id="+id)
+	run(t, "mkdir -p -path=/home/docs")
+	run(t, "mkfile -path=/home/docs/a.txt -size=12")
+	run(t, "mkgrp -name=ventas")
+	run(t, "mkusr -user=ana -pass=123 -grp=ventas")
+	run(t, "mkgrp -name=compras")
+	run(t, "chgrp -usr=ana -grp=compras")
+	run(t, "rmusr -usr=ana")
+	run(t, "rmgrp -name=ventas")
+
+	// Un comando que falla no deja su entrada en el journal
+	if _, err := Analyzer("mkdir -path=/home/docs"); err == nil {
+		t.Fatal("mkdir de una carpeta existente no falló")
+	}
+
+	report := filepath.Join(dir, "journal.json")
+	run(t, "rep -id="+id+" -path="+report+" -name=journaling")
+	content, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []struct {
+		Count     int32  `json:"count"`
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content"`
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ operation, path, content string }{
+		{"mkdir", "/home/docs", ""},
+		{"mkfile", "/home/docs/a.txt", "012345678901"},
+		{"mkgrp", "/users.txt", "ventas"},
+		{"mkusr", "/users.txt", "ana,ventas"},
+		{"mkgrp", "/users.txt", "compras"},
+		{"chgrp", "/users.txt", "ana,compras"},
+		{"rmusr", "/users.txt", "ana"},
+		{"rmgrp", "/users.txt", "ventas"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("el journal tiene %d entradas, se esperaban %d:\n%s", len(entries), len(want), content)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Count != int32(i+1) || e.Operation != w.operation || e.Path != w.path || e.Content != w.content {
+			t.Fatalf("entrada %d = %+v, se esperaba %d %+v", i, e, i+1, w)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	structs "backend/Structs"
+	globals "backend/globals"
+	"regexp"
+	"strings"
+)
+
+// diskAccess indica cómo se obtiene el disco que usa un comando y si lo modifica
+type diskAccess struct {
+	param     string // Parámetro que identifica el disco: "-path", "-id" o "" para la partición de la sesión activa
+	write     bool   // El comando escribe en el disco
+	writeFlag string // Opción que convierte el comando en escritura, por ejemplo fsck -repair
+}
+
+// commandDiskAccess define el disco de cada comando, los comandos que no aparecen no usan un disco
+// lsdisk y mounted bloquean cada disco por separado mientras lo leen
+var commandDiskAccess = map[string]diskAccess{
+	"mkdisk":   {param: "-path", write: true},
+	"rmdisk":   {param: "-path", write: true},
+	"fdisk":    {param: "-path", write: true},
+	"mount":    {param: "-path", write: true},
+	"mkfs":     {param: "-id", write: true},
+	"fsck":     {param: "-id", writeFlag: "-repair"},
+	"rep":      {param: "-id"},
+	"login":    {param: "-id"},
+	"mkgrp":    {write: true},
+	"rmgrp":    {write: true},
+	"mkusr":    {write: true},
+	"rmusr":    {write: true},
+	"chgrp":    {write: true},
+	"setadmin": {write: true},
+	"mkfile":   {write: true},
+	"mkdir":    {write: true},
+	"cat":      {},
+}
+
+var (
+	pathParamRe = regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
+	idParamRe   = regexp.MustCompile(`-id=[^\s]+`)
+)
+
+// lockCommandDisk bloquea el disco que usa el comando y devuelve su path, si el comando escribe en él
+// y la función que lo libera
+// Si el disco no se puede determinar no se bloquea nada y el comando informa el error al ejecutarse
+func lockCommandDisk(command string, args []string, user *structs.User) (string, bool, func()) {
+	access, exists := commandDiskAccess[command]
+	if !exists {
+		return "", false, func() {}
+	}
+
+	path := commandDiskPath(access, strings.Join(args, " "), user)
+	if path == "" {
+		return "", false, func() {}
+	}
+
+	if access.write || (access.writeFlag != "" && containsFlag(args, access.writeFlag)) {
+		return path, true, globals.LockDisk(path)
+	}
+	return path, false, globals.RLockDisk(path)
+}
+
+// commandDiskPath obtiene el path del disco a partir de los parámetros o de la partición del usuario
+func commandDiskPath(access diskAccess, args string, user *structs.User) string {
+	switch access.param {
+	case "-path":
+		match := pathParamRe.FindString(args)
+		if match == "" {
+			return ""
+		}
+		return strings.Trim(strings.SplitN(match, "=", 2)[1], "\"")
+	case "-id":
+		match := idParamRe.FindString(args)
+		if match == "" {
+			return ""
+		}
+		return globals.GetMountedPath(strings.Trim(strings.SplitN(match, "=", 2)[1], "\""))
+	default:
+		if !globals.IsLoggedIn(user) {
+			return ""
+		}
+		return globals.GetMountedPath(user.Id)
+	}
+}
+
+// containsFlag verifica si la opción aparece entre los argumentos
+func containsFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
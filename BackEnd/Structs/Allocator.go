@@ -0,0 +1,231 @@
+package structs
+
+import (
+	"fmt"
+	"os"
+)
+
+// Todas las asignaciones y liberaciones de inodos y bloques pasan por este archivo
+// S_inodes_count y S_blocks_count son los totales fijados por mkfs, nunca cambian
+// S_free_inodes_count y S_free_blocks_count se actualizan solo cuando cambia un bit del bitmap
+// S_first_ino y S_first_blo apuntan al primer inodo y bloque libre, o son -1 si no quedan libres
+
+// AllocateInode reserva el primer inodo libre del bitmap y devuelve su índice
+func (sb *Superblock) AllocateInode(file *os.File) (int32, error) {
+	bitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return -1, fmt.Errorf("error al asignar un inodo: %w", err)
+	}
+	index, next, err := sb.allocate(file, bitmap, sb.firstFreeIndex(sb.S_first_ino, sb.S_inode_start, sb.S_inode_size), &sb.S_free_inodes_count)
+	if err != nil {
+		return -1, fmt.Errorf("error al asignar un inodo: %w", err)
+	}
+	sb.S_first_ino = freeOffset(next, sb.S_inode_start, sb.S_inode_size)
+
+	fmt.Printf("Inodo asignado: %d\n", index) // Depuración
+	return index, nil
+}
+
+// AllocateBlock reserva el primer bloque libre del bitmap y devuelve su índice
+func (sb *Superblock) AllocateBlock(file *os.File) (int32, error) {
+	bitmap, err := sb.blockBitmap(file)
+	if err != nil {
+		return -1, fmt.Errorf("error al asignar un bloque: %w", err)
+	}
+	index, next, err := sb.allocate(file, bitmap, sb.firstFreeIndex(sb.S_first_blo, sb.S_block_start, sb.S_block_size), &sb.S_free_blocks_count)
+	if err != nil {
+		return -1, fmt.Errorf("error al asignar un bloque: %w", err)
+	}
+	sb.S_first_blo = freeOffset(next, sb.S_block_start, sb.S_block_size)
+
+	fmt.Printf("Bloque asignado: %d\n", index) // Depuración
+	return index, nil
+}
+
+// AllocateBlockRange reserva count bloques consecutivos elegidos con el ajuste fit (FirstFit, BestFit o WorstFit)
+// Si ningún hueco alcanza reserva los bloques uno por uno donde haya espacio
+// Devuelve los índices reservados en orden
+func (sb *Superblock) AllocateBlockRange(file *os.File, count int32, fit byte) ([]int32, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	if sb.S_free_blocks_count < count {
+		return nil, fmt.Errorf("error al asignar %d bloques: no hay espacio disponible", count)
+	}
+
+	bitmap, err := sb.blockBitmap(file)
+	if err != nil {
+		return nil, fmt.Errorf("error al asignar %d bloques: %w", count, err)
+	}
+
+	start := bitmap.FindFreeRange(count, fit)
+	if start == -1 {
+		// El espacio libre está fragmentado
+		indexes := make([]int32, 0, count)
+		for len(indexes) < int(count) {
+			index, err := sb.AllocateBlock(file)
+			if err != nil {
+				return nil, err
+			}
+			indexes = append(indexes, index)
+		}
+		return indexes, nil
+	}
+
+	err = bitmap.SetRange(file, start, start+count, true)
+	if err != nil {
+		return nil, fmt.Errorf("error al asignar %d bloques: %w", count, err)
+	}
+	sb.S_free_blocks_count -= count
+
+	// El primer libre solo cambia si el rango lo incluía
+	first := sb.firstFreeIndex(sb.S_first_blo, sb.S_block_start, sb.S_block_size)
+	if first == -1 || (first >= start && first < start+count) {
+		sb.S_first_blo = freeOffset(bitmap.FindFree(0), sb.S_block_start, sb.S_block_size)
+	}
+
+	indexes := make([]int32, count)
+	for i := range indexes {
+		indexes[i] = start + int32(i)
+	}
+	fmt.Printf("Bloques asignados: %d-%d\n", start, start+count-1) // Depuración
+	return indexes, nil
+}
+
+// FreeInode libera un inodo en el bitmap
+func (sb *Superblock) FreeInode(file *os.File, index int32) error {
+	bitmap, err := sb.inodeBitmap(file)
+	if err == nil {
+		err = sb.release(file, bitmap, index, &sb.S_free_inodes_count)
+	}
+	if err != nil {
+		return fmt.Errorf("error al liberar el inodo %d: %w", index, err)
+	}
+
+	// El inodo liberado pasa a ser el primero libre si está antes del actual
+	first := sb.firstFreeIndex(sb.S_first_ino, sb.S_inode_start, sb.S_inode_size)
+	if first == -1 || index < first {
+		sb.S_first_ino = freeOffset(index, sb.S_inode_start, sb.S_inode_size)
+	}
+	return nil
+}
+
+// FreeBlock libera un bloque en el bitmap
+func (sb *Superblock) FreeBlock(file *os.File, index int32) error {
+	bitmap, err := sb.blockBitmap(file)
+	if err == nil {
+		err = sb.release(file, bitmap, index, &sb.S_free_blocks_count)
+	}
+	if err != nil {
+		return fmt.Errorf("error al liberar el bloque %d: %w", index, err)
+	}
+
+	// El bloque liberado pasa a ser el primero libre si está antes del actual
+	first := sb.firstFreeIndex(sb.S_first_blo, sb.S_block_start, sb.S_block_size)
+	if first == -1 || index < first {
+		sb.S_first_blo = freeOffset(index, sb.S_block_start, sb.S_block_size)
+	}
+	return nil
+}
+
+// SyncFreeCounts recalcula los contadores de libres y los primeros libres a partir de los bitmaps
+// Se usa después de modificar los bitmaps directamente, por ejemplo al reparar con fsck
+func (sb *Superblock) SyncFreeCounts(file *os.File) error {
+	inodeBitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap de inodos: %w", err)
+	}
+	blockBitmap, err := sb.blockBitmap(file)
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap de bloques: %w", err)
+	}
+
+	sb.S_free_inodes_count = sb.S_inodes_count - inodeBitmap.Count()
+	sb.S_free_blocks_count = sb.S_blocks_count - blockBitmap.Count()
+	sb.S_first_ino = freeOffset(inodeBitmap.FindFree(0), sb.S_inode_start, sb.S_inode_size)
+	sb.S_first_blo = freeOffset(blockBitmap.FindFree(0), sb.S_block_start, sb.S_block_size)
+	return nil
+}
+
+// UsedInodes devuelve la cantidad de inodos en uso
+func (sb *Superblock) UsedInodes() int32 {
+	return sb.S_inodes_count - sb.S_free_inodes_count
+}
+
+// UsedBlocks devuelve la cantidad de bloques en uso
+func (sb *Superblock) UsedBlocks() int32 {
+	return sb.S_blocks_count - sb.S_free_blocks_count
+}
+
+// IsInodeUsed indica si el inodo está marcado como ocupado en el bitmap
+func (sb *Superblock) IsInodeUsed(file *os.File, index int32) (bool, error) {
+	if index < 0 || index >= sb.S_inodes_count {
+		return false, fmt.Errorf("índice de inodo fuera de rango: %d", index)
+	}
+	bitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return false, err
+	}
+	return bitmap.IsSet(index), nil
+}
+
+// allocate marca como ocupado el primer bit libre del bitmap, buscando desde hint
+// Devuelve el índice asignado y el siguiente libre (-1 si ya no quedan)
+func (sb *Superblock) allocate(file *os.File, bitmap *Bitmap, hint int32, free *int32) (int32, int32, error) {
+	if *free <= 0 {
+		return -1, -1, fmt.Errorf("no hay espacio disponible")
+	}
+
+	// Si el primer libre registrado no es válido se busca desde el inicio
+	index := int32(-1)
+	if hint >= 0 {
+		index = bitmap.FindFree(hint)
+	}
+	if index == -1 {
+		index = bitmap.FindFree(0)
+	}
+	if index == -1 {
+		return -1, -1, fmt.Errorf("el bitmap no tiene posiciones libres aunque el superbloque indica %d", *free)
+	}
+
+	err := bitmap.Set(file, index, true)
+	if err != nil {
+		return -1, -1, err
+	}
+	*free--
+
+	return index, bitmap.FindFree(index + 1), nil
+}
+
+// release marca como libre una posición ocupada del bitmap
+func (sb *Superblock) release(file *os.File, bitmap *Bitmap, index int32, free *int32) error {
+	if index < 0 || index >= bitmap.count {
+		return fmt.Errorf("índice fuera de rango")
+	}
+	if !bitmap.IsSet(index) {
+		return fmt.Errorf("ya está libre")
+	}
+
+	err := bitmap.Set(file, index, false)
+	if err != nil {
+		return err
+	}
+	*free++
+	return nil
+}
+
+// firstFreeIndex convierte el desplazamiento del primer libre en un índice, -1 si no hay libres
+func (sb *Superblock) firstFreeIndex(offset int32, tableStart int32, size int32) int32 {
+	if offset < tableStart {
+		return -1
+	}
+	return (offset - tableStart) / size
+}
+
+// freeOffset convierte el índice del primer libre en el desplazamiento guardado en el superbloque
+func freeOffset(index int32, tableStart int32, size int32) int32 {
+	if index == -1 {
+		return -1
+	}
+	return tableStart + index*size
+}
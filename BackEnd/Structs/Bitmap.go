@@ -1,8 +1,10 @@
 package structs
 
 import (
+	utilidades "backend/utils"
 	"encoding/binary"
 	"fmt"
+	"math/bits"
 	"os"
 )
 
@@ -11,16 +13,32 @@ const (
 	OccupiedBlockBit = 1
 )
 
+// Ajustes para reservar rangos contiguos de bloques, los mismos que usan las particiones
+const (
+	FirstFit = 'F'
+	BestFit  = 'B'
+	WorstFit = 'W'
+)
+
+// Bitmap es la copia en memoria de un bitmap de la partición
+// Se carga una vez por comando y cada cambio se escribe de inmediato en el disco, solo los bytes modificados
+// Los bits se agrupan en palabras de 64 para buscar libres y ocupados de 64 en 64
+type Bitmap struct {
+	start int32    // Posición del bitmap en el disco
+	count int32    // Cantidad de bits del bitmap
+	words []uint64 // El bit i está en words[i/64], en la posición i%64
+}
+
 // CreateBitMaps crea los Bitmaps de inodos y bloques en el archivo especificado
 func (sb *Superblock) CreateBitMaps(file *os.File) error {
 	// Crear el bitmap de inodos
-	err := sb.createBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count+sb.S_free_inodes_count, false)
+	err := sb.createBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count, false)
 	if err != nil {
 		return fmt.Errorf("error creando bitmap de inodos: %w", err)
 	}
 
 	// Crear el bitmap de bloques
-	err = sb.createBitmap(file, sb.S_bm_block_start, sb.S_blocks_count+sb.S_free_blocks_count, false)
+	err = sb.createBitmap(file, sb.S_bm_block_start, sb.S_blocks_count, false)
 	if err != nil {
 		return fmt.Errorf("error creando bitmap de bloques: %w", err)
 	}
@@ -31,11 +49,6 @@ func (sb *Superblock) CreateBitMaps(file *os.File) error {
 // createBitmap es una función auxiliar que escribe un bitmap en el archivo
 // Cada bloque o inodo está representado por un bit
 func (sb *Superblock) createBitmap(file *os.File, start int32, count int32, occupied bool) error {
-	_, err := file.Seek(int64(start), 0)
-	if err != nil {
-		return fmt.Errorf("error buscando el inicio del bitmap: %w", err)
-	}
-
 	// Calcular el número de bytes necesarios (cada byte tiene 8 bits)
 	byteCount := (count + 7) / 8
 
@@ -51,105 +64,196 @@ func (sb *Superblock) createBitmap(file *os.File, start int32, count int32, occu
 	}
 
 	// Escribir el buffer en el archivo
-	err = binary.Write(file, binary.LittleEndian, buffer)
+	err := utilidades.WriteBytes(file, int64(start), buffer)
 	if err != nil {
 		return fmt.Errorf("error escribiendo el bitmap: %w", err)
 	}
 
+	// La copia en memoria que se haya cargado antes ya no coincide con el disco
+	utilidades.DropTransactionValue(file, bitmapKey{start, count})
 	return nil
 }
 
 // UpdateBitmapInode actualiza el bitmap de inodos
 func (sb *Superblock) UpdateBitmapInode(file *os.File, position int32, occupied bool) error {
-	return sb.updateBitmap(file, sb.S_bm_inode_start, position, occupied)
+	bitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return err
+	}
+	return bitmap.Set(file, position, occupied)
 }
 
 // UpdateBitmapBlock actualiza el bitmap de bloques
 func (sb *Superblock) UpdateBitmapBlock(file *os.File, position int32, occupied bool) error {
-	return sb.updateBitmap(file, sb.S_bm_block_start, position, occupied)
+	bitmap, err := sb.blockBitmap(file)
+	if err != nil {
+		return err
+	}
+	return bitmap.Set(file, position, occupied)
+}
+
+// inodeBitmap devuelve el bitmap de inodos en memoria
+func (sb *Superblock) inodeBitmap(file *os.File) (*Bitmap, error) {
+	return loadBitmap(file, sb.S_bm_inode_start, sb.S_inodes_count)
+}
+
+// blockBitmap devuelve el bitmap de bloques en memoria
+func (sb *Superblock) blockBitmap(file *os.File) (*Bitmap, error) {
+	return loadBitmap(file, sb.S_bm_block_start, sb.S_blocks_count)
 }
 
-// updateBitmap es una función auxiliar que actualiza un bit en un bitmap
-func (sb *Superblock) updateBitmap(file *os.File, start int32, position int32, occupied bool) error {
-	// Calcular el byte y el bit dentro de ese byte
-	byteIndex := position / 8
-	bitOffset := position % 8
+// bitmapKey identifica el bitmap entre los valores de la transacción del disco
+type bitmapKey struct {
+	start int32
+	count int32
+}
 
-	// Mover el puntero al byte correspondiente
-	_, err := file.Seek(int64(start)+int64(byteIndex), 0)
+// loadBitmap devuelve el bitmap en memoria, lo lee del disco solo la primera vez en el comando
+func loadBitmap(file *os.File, start int32, count int32) (*Bitmap, error) {
+	value, err := utilidades.TransactionValue(file, bitmapKey{start, count}, func() (any, error) {
+		return readBitmap(file, start, count)
+	})
 	if err != nil {
-		return fmt.Errorf("error buscando la posición en el bitmap: %w", err)
+		return nil, err
 	}
+	return value.(*Bitmap), nil
+}
 
-	// Leer el byte actual
-	var byteVal byte
-	err = binary.Read(file, binary.LittleEndian, &byteVal)
+// readBitmap lee un bitmap completo desde el archivo
+func readBitmap(file *os.File, start int32, count int32) (*Bitmap, error) {
+	buffer := make([]byte, (count+63)/64*8)
+	err := utilidades.ReadBytes(file, int64(start), buffer[:(count+7)/8])
 	if err != nil {
-		return fmt.Errorf("error leyendo el byte del bitmap: %w", err)
+		return nil, fmt.Errorf("error leyendo el bitmap: %w", err)
 	}
 
-	// Actualizar el bit correspondiente dentro del byte
-	if occupied {
-		byteVal |= (1 << bitOffset) // Poner el bit a 1 (ocupado)
-	} else {
-		byteVal &= ^(1 << bitOffset) // Poner el bit a 0 (libre)
+	// El bit i%8 del byte i/8 queda en la misma posición al leer las palabras en little endian
+	bitmap := &Bitmap{start: start, count: count, words: make([]uint64, len(buffer)/8)}
+	for i := range bitmap.words {
+		bitmap.words[i] = binary.LittleEndian.Uint64(buffer[i*8:])
 	}
+	return bitmap, nil
+}
 
-	// Mover el puntero de nuevo al byte correspondiente
-	_, err = file.Seek(int64(start)+int64(byteIndex), 0)
-	if err != nil {
-		return fmt.Errorf("error buscando la posición en el bitmap para escribir: %w", err)
+// validMask devuelve los bits de la palabra que corresponden a posiciones del bitmap
+func (b *Bitmap) validMask(word int) uint64 {
+	remaining := b.count - int32(word)*64
+	if remaining >= 64 {
+		return ^uint64(0)
 	}
+	return uint64(1)<<remaining - 1
+}
 
-	// Escribir el byte actualizado de vuelta en el archivo
-	err = binary.Write(file, binary.LittleEndian, &byteVal)
-	if err != nil {
-		return fmt.Errorf("error escribiendo el byte actualizado del bitmap: %w", err)
-	}
+// IsSet indica si el bit de la posición está en 1 (ocupado)
+func (b *Bitmap) IsSet(position int32) bool {
+	return b.words[position/64]&(1<<(position%64)) != 0
+}
 
-	return nil
+// Set pone el bit de la posición en 1 (ocupado) o 0 (libre) y lo escribe en el disco
+func (b *Bitmap) Set(file *os.File, position int32, occupied bool) error {
+	return b.SetRange(file, position, position+1, occupied)
 }
 
-// isBlockFree verifica si un bloque en el bitmap está libre
-func (sb *Superblock) isBlockFree(file *os.File, start int32, position int32) (bool, error) {
-	// Calcular el byte y el bit dentro del byte
-	byteIndex := position / 8
-	bitOffset := position % 8
+// SetRange pone los bits de las posiciones [from, to) en 1 (ocupados) o 0 (libres)
+// Solo se escriben en el disco los bytes que contienen esas posiciones
+func (b *Bitmap) SetRange(file *os.File, from int32, to int32, occupied bool) error {
+	if from < 0 || to > b.count || from >= to {
+		return fmt.Errorf("rango fuera del bitmap: %d-%d", from, to)
+	}
 
-	// Mover el puntero al byte correspondiente
-	_, err := file.Seek(int64(start)+int64(byteIndex), 0)
-	if err != nil {
-		return false, fmt.Errorf("error buscando la posición en el bitmap: %w", err)
+	for word := from / 64; word <= (to-1)/64; word++ {
+		mask := ^uint64(0)
+		if word == from/64 {
+			mask &= ^uint64(0) << (from % 64)
+		}
+		if word == (to-1)/64 {
+			mask &= ^uint64(0) >> (63 - (to-1)%64)
+		}
+		if occupied {
+			b.words[word] |= mask
+		} else {
+			b.words[word] &^= mask
+		}
 	}
 
-	// Leer el byte actual
-	var byteVal byte
-	err = binary.Read(file, binary.LittleEndian, &byteVal)
+	firstByte, lastByte := from/8, (to-1)/8
+	changed := make([]byte, lastByte-firstByte+1)
+	for i := range changed {
+		position := firstByte + int32(i)
+		changed[i] = byte(b.words[position/8] >> (position % 8 * 8))
+	}
+	err := utilidades.WriteBytes(file, int64(b.start+firstByte), changed)
 	if err != nil {
-		return false, fmt.Errorf("error leyendo el byte del bitmap: %w", err)
+		return fmt.Errorf("error escribiendo el bitmap: %w", err)
 	}
-
-	// Verificar si el bit está libre (0) o ocupado (1)
-	return (byteVal & (1 << bitOffset)) == 0, nil
+	return nil
 }
 
-// isInodeFree verifica si un inodo en el bitmap está libre
-func (sb *Superblock) isInodeFree(file *os.File, start int32, position int32) (bool, error) {
-	byteIndex := position / 8 // Calcular el byte dentro del bitmap
-	bitOffset := position % 8 // Calcular el bit dentro del byte
+// Count cuenta los bits en 1 del bitmap
+func (b *Bitmap) Count() int32 {
+	used := 0
+	for i, word := range b.words {
+		used += bits.OnesCount64(word & b.validMask(i))
+	}
+	return int32(used)
+}
 
-	// Leer el byte que contiene el bit correspondiente al inodo
-	_, err := file.Seek(int64(start)+int64(byteIndex), 0)
-	if err != nil {
-		return false, fmt.Errorf("error buscando el byte en el bitmap de inodos: %w", err)
+// FindFree busca el primer bit en 0 a partir de la posición indicada, devuelve -1 si no hay
+func (b *Bitmap) FindFree(from int32) int32 {
+	if from < 0 || from >= b.count {
+		return -1
 	}
+	for word := int(from / 64); word < len(b.words); word++ {
+		free := ^b.words[word] & b.validMask(word)
+		if word == int(from/64) {
+			free &= ^uint64(0) << (from % 64)
+		}
+		if free != 0 {
+			return int32(word)*64 + int32(bits.TrailingZeros64(free))
+		}
+	}
+	return -1
+}
 
-	var byteVal byte
-	err = binary.Read(file, binary.LittleEndian, &byteVal)
-	if err != nil {
-		return false, fmt.Errorf("error leyendo el byte del bitmap de inodos: %w", err)
+// findUsed busca el primer bit en 1 a partir de la posición indicada, devuelve count si no hay
+func (b *Bitmap) findUsed(from int32) int32 {
+	for word := int(from / 64); word < len(b.words); word++ {
+		used := b.words[word] & b.validMask(word)
+		if word == int(from/64) {
+			used &= ^uint64(0) << (from % 64)
+		}
+		if used != 0 {
+			return int32(word)*64 + int32(bits.TrailingZeros64(used))
+		}
 	}
+	return b.count
+}
 
-	// Verificar si el bit correspondiente está en 0 (libre)
-	return (byteVal & (1 << bitOffset)) == 0, nil
+// FindFreeRange busca length bits libres consecutivos según el ajuste y devuelve la posición del primero
+// FirstFit usa el primer hueco que alcanza, BestFit el más pequeño y WorstFit el más grande
+// Devuelve -1 si ningún hueco alcanza
+func (b *Bitmap) FindFreeRange(length int32, fit byte) int32 {
+	found, foundLength := int32(-1), int32(0)
+	for start := b.FindFree(0); start != -1; {
+		end := b.findUsed(start)
+		if size := end - start; size >= length {
+			switch fit {
+			case BestFit:
+				if size == length {
+					return start // No hay un hueco más ajustado
+				}
+				if found == -1 || size < foundLength {
+					found, foundLength = start, size
+				}
+			case WorstFit:
+				if size > foundLength {
+					found, foundLength = start, size
+				}
+			default:
+				return start
+			}
+		}
+		start = b.FindFree(end)
+	}
+	return found
 }
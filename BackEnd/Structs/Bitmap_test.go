@@ -0,0 +1,140 @@
+package structs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestBitmap crea un bitmap de count bits en una imagen temporal con las posiciones de used ocupadas
+// Cada elemento de used es un rango [desde, hasta)
+func newTestBitmap(t *testing.T, count int32, used [][2]int32) (*os.File, *Bitmap) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "disco.mia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	err = file.Truncate(int64(count+7) / 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bitmap, err := readBitmap(file, 0, count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range used {
+		if err := bitmap.SetRange(file, r[0], r[1], true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return file, bitmap
+}
+
+func TestBitmapSetRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int32
+		used     [][2]int32
+		from, to int32
+		occupied bool
+		wantErr  bool
+	}{
+		{name: "un bit", count: 128, from: 5, to: 6, occupied: true},
+		{name: "cruza el límite de una palabra", count: 128, from: 60, to: 70, occupied: true},
+		{name: "palabra completa", count: 192, from: 64, to: 128, occupied: true},
+		{name: "última palabra incompleta", count: 100, from: 90, to: 100, occupied: true},
+		{name: "libera dentro de un rango ocupado", count: 128, used: [][2]int32{{0, 128}}, from: 30, to: 100},
+		{name: "rango vacío", count: 128, from: 10, to: 10, occupied: true, wantErr: true},
+		{name: "termina fuera del bitmap", count: 100, from: 90, to: 101, occupied: true, wantErr: true},
+		{name: "empieza antes del bitmap", count: 100, from: -1, to: 5, occupied: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, bitmap := newTestBitmap(t, tt.count, tt.used)
+
+			// Estado esperado de cada bit
+			want := make([]bool, tt.count)
+			for _, r := range tt.used {
+				for i := r[0]; i < r[1]; i++ {
+					want[i] = true
+				}
+			}
+
+			err := bitmap.SetRange(file, tt.from, tt.to, tt.occupied)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetRange(%d, %d) error = %v, se esperaba error: %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				for i := tt.from; i < tt.to; i++ {
+					want[i] = tt.occupied
+				}
+			}
+
+			// La copia en memoria y el disco deben coincidir con lo esperado
+			onDisk, err := readBitmap(file, 0, tt.count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCount := int32(0)
+			for i := int32(0); i < tt.count; i++ {
+				if bitmap.IsSet(i) != want[i] {
+					t.Fatalf("bit %d en memoria = %v, se esperaba %v", i, bitmap.IsSet(i), want[i])
+				}
+				if onDisk.IsSet(i) != want[i] {
+					t.Fatalf("bit %d en el disco = %v, se esperaba %v", i, onDisk.IsSet(i), want[i])
+				}
+				if want[i] {
+					wantCount++
+				}
+			}
+			if bitmap.Count() != wantCount {
+				t.Fatalf("Count() = %d, se esperaba %d", bitmap.Count(), wantCount)
+			}
+		})
+	}
+}
+
+func TestBitmapFindFreeRange(t *testing.T) {
+	// Huecos libres: [10, 15) de 5, [40, 48) de 8 y [60, 128) de 68, el último cruza el límite de una palabra
+	used := [][2]int32{{0, 10}, {15, 40}, {48, 60}}
+
+	tests := []struct {
+		name   string
+		length int32
+		fit    byte
+		want   int32
+	}{
+		{name: "first fit usa el primer hueco", length: 5, fit: FirstFit, want: 10},
+		{name: "first fit salta los huecos pequeños", length: 6, fit: FirstFit, want: 40},
+		{name: "first fit en el último hueco", length: 68, fit: FirstFit, want: 60},
+		{name: "best fit con hueco exacto", length: 8, fit: BestFit, want: 40},
+		{name: "best fit usa el hueco más pequeño", length: 4, fit: BestFit, want: 10},
+		{name: "best fit cuando solo alcanza el mayor", length: 9, fit: BestFit, want: 60},
+		{name: "worst fit usa el hueco más grande", length: 1, fit: WorstFit, want: 60},
+		{name: "ningún hueco alcanza", length: 69, fit: FirstFit, want: -1},
+		{name: "ningún hueco alcanza con worst fit", length: 69, fit: WorstFit, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, bitmap := newTestBitmap(t, 128, used)
+			if got := bitmap.FindFreeRange(tt.length, tt.fit); got != tt.want {
+				t.Fatalf("FindFreeRange(%d, %c) = %d, se esperaba %d", tt.length, tt.fit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitmapFindFreeRangeFull(t *testing.T) {
+	_, bitmap := newTestBitmap(t, 70, [][2]int32{{0, 70}})
+	if got := bitmap.FindFreeRange(1, FirstFit); got != -1 {
+		t.Fatalf("FindFreeRange() en un bitmap lleno = %d, se esperaba -1", got)
+	}
+	if got := bitmap.FindFree(0); got != -1 {
+		t.Fatalf("FindFree() en un bitmap lleno = %d, se esperaba -1", got)
+	}
+}
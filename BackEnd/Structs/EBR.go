@@ -134,6 +134,27 @@ func FindLastEBR(start int32, file *os.File) (*EBR, error) {
 	return currentEBR, nil
 }
 
+// ReadEBRChain recorre la lista enlazada de EBRs a partir del inicio de la partición extendida
+func ReadEBRChain(start int32, file *os.File) ([]*EBR, error) {
+	var chain []*EBR
+	visited := make(map[int32]bool) // Evita ciclos en cadenas corruptas
+
+	for current := start; current != -1; {
+		if current < 0 || visited[current] {
+			return nil, fmt.Errorf("cadena de EBR inválida en la posición: %d", current)
+		}
+		visited[current] = true
+
+		ebr, err := ReadEBR(current, file)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, ebr)
+		current = ebr.Ebr_next
+	}
+	return chain, nil
+}
+
 // SetNextEBR establece el apuntador al siguiente EBR en la lista enlazada de EBRs
 func (e *EBR) SetNextEBR(newNext int32) {
 	fmt.Printf("Estableciendo el siguiente EBR: Actual Start: %d, Nuevo Next: %d\n", e.Ebr_start, newNext)
@@ -7,12 +7,21 @@ import (
 	"strings"
 )
 
-// Definimos una constante para el tamaño del bloque
-const BlockSize = 64
+// DefaultBlockSize es el tamaño de bloque usado cuando mkfs no recibe -bs
+const DefaultBlockSize = 64
+
+// MinBlockSize es el tamaño de bloque más pequeño que acepta mkfs
+// Los bloques de carpeta y de apuntadores ocupan siempre estos 64 bytes al inicio del bloque, con cualquier -bs
+// Solo los bloques de archivo usan el tamaño completo, en los demás el resto del bloque queda sin usar
+const MinBlockSize = 64
 
 type FileBlock struct {
-	B_content [BlockSize]byte
-	// Total: 64 bytes
+	B_content []byte // Contenido del bloque, su largo es el tamaño de bloque del superbloque (S_block_size)
+}
+
+// NewFileBlock crea un bloque de archivo vacío del tamaño de bloque de la partición
+func (sb *Superblock) NewFileBlock() *FileBlock {
+	return &FileBlock{B_content: make([]byte, sb.S_block_size)}
 }
 
 // Encode serializa la estructura FileBlock en un archivo binario en la posición especificada
@@ -27,8 +36,13 @@ func (fb *FileBlock) Encode(file *os.File, offset int64) error {
 
 // Decode deserializa la estructura FileBlock desde un archivo binario en la posición especificada
 func (fb *FileBlock) Decode(file *os.File, offset int64) error {
+	// El tamaño a leer lo define B_content, se crea con Superblock.NewFileBlock
+	if len(fb.B_content) == 0 {
+		return fmt.Errorf("error reading FileBlock from file: el bloque no tiene tamaño asignado")
+	}
+
 	// Utilizamos la función ReadFromFile del paquete utils
-	err := utils.ReadFromFile(file, offset, &fb.B_content)
+	err := utils.ReadFromFile(file, offset, fb.B_content)
 	if err != nil {
 		return fmt.Errorf("error reading FileBlock from file: %w", err)
 	}
@@ -51,8 +65,8 @@ func (fb *FileBlock) GetContent() string {
 
 // SetContent copia una cadena en B_content, asegurando que no exceda el tamaño máximo
 func (fb *FileBlock) SetContent(content string) error {
-	if len(content) > BlockSize {
-		return fmt.Errorf("el tamaño del contenido excede el tamaño del bloque de %d bytes", BlockSize)
+	if len(content) > len(fb.B_content) {
+		return fmt.Errorf("el tamaño del contenido excede el tamaño del bloque de %d bytes", len(fb.B_content))
 	}
 	// Limpiar B_content
 	fb.ClearContent()
@@ -63,7 +77,7 @@ func (fb *FileBlock) SetContent(content string) error {
 
 // EspacioDisponible retorna la cantidad de bytes disponibles en el bloque
 func (fb *FileBlock) EspacioDisponible() int {
-	return BlockSize - fb.EspacioUsado()
+	return len(fb.B_content) - fb.EspacioUsado()
 }
 
 // TieneEspacio verifica si aún queda espacio en el bloque
@@ -102,9 +116,9 @@ func (fb *FileBlock) ClearContent() {
 	}
 }
 
-// NewFileBlock crea un nuevo FileBlock con contenido opcional
-func NewFileBlock(content string) (*FileBlock, error) {
-	fb := &FileBlock{}
+// NewFileBlock crea un nuevo FileBlock del tamaño indicado con contenido opcional
+func NewFileBlock(blockSize int32, content string) (*FileBlock, error) {
+	fb := &FileBlock{B_content: make([]byte, blockSize)}
 	err := fb.SetContent(content)
 	if err != nil {
 		return nil, err
@@ -112,15 +126,15 @@ func NewFileBlock(content string) (*FileBlock, error) {
 	return fb, nil
 }
 
-// SplitContent divide una cadena en bloques de tamaño BlockSize y retorna un slice de FileBlocks
-func SplitContent(content string) ([]*FileBlock, error) {
+// SplitContent divide una cadena en bloques de tamaño blockSize y retorna un slice de FileBlocks
+func SplitContent(content string, blockSize int32) ([]*FileBlock, error) {
 	var blocks []*FileBlock
 	for len(content) > 0 {
-		end := BlockSize
-		if len(content) < BlockSize {
+		end := int(blockSize)
+		if len(content) < end {
 			end = len(content)
 		}
-		fb, err := NewFileBlock(content[:end])
+		fb, err := NewFileBlock(blockSize, content[:end])
 		if err != nil {
 			return nil, err
 		}
@@ -1,186 +1,194 @@
 package structs
 
 import (
-	"backend/utils"
 	"fmt" // Importamos fmt para los mensajes de depuración
 	"os"
-	"strings"
 	"time"
 )
 
-// createFolderinode crea una carpeta en un inodo específico
-func (sb *Superblock) createFileInInode(file *os.File, inodeIndex int32, parentsDir []string, destFile string, fileSize int, fileContent []string) error {
-	// Crear un nuevo inodo
-	fmt.Printf("Intentando crear archivo '%s' en inodo index %d\n", destFile, inodeIndex) // Depuración
-	inode := &Inode{}
-	// Deserializar el inodo
-	err := inode.Decode(file, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+// CreateFile crea el archivo indicado por path con el contenido dividido en bloques, su carpeta padre debe existir
+// fit es el ajuste de la partición, se usa para elegir dónde reservar los bloques del archivo
+func (sb *Superblock) CreateFile(file *os.File, path string, size int, cont []string, fit byte) error {
+	// Buscar la carpeta padre recorriendo el path desde la raíz
+	parentIndex, name, err := sb.ResolveParent(file, path)
 	if err != nil {
-		return fmt.Errorf("Error al deserializar inodo %d: %v", inodeIndex, err)
+		return err
 	}
+	fmt.Printf("Creando archivo '%s' con tamaño %d en el inodo %d\n", name, size, parentIndex) // Depuración
 
-	// Verificar si el inodo es de tipo carpeta
-	if inode.I_type[0] == '1' {
-		fmt.Printf("El inodo %d es una carpeta, omitiendo.\n", inodeIndex) // Depuración
-		return nil
+	// Verificar que el contenido quepa en los bloques directos e indirectos del inodo antes de reservar nada
+	if len(cont) > MaxFileBlocks {
+		return fmt.Errorf("Error al crear el archivo '%s': necesita %d bloques y un inodo puede tener como máximo %d", name, len(cont), MaxFileBlocks)
 	}
 
-	// Iterar sobre cada bloque del inodo (apuntadores)
-	for _, blockIndex := range inode.I_block {
-		// Si el bloque no existe, salir
-		if blockIndex == -1 {
-			fmt.Printf("El inodo %d no tiene más bloques, saliendo.\n", inodeIndex) // Depuración
-			break
-		}
+	// Reservar el inodo del archivo
+	newInodeIndex, err := sb.AllocateInode(file)
+	if err != nil {
+		return fmt.Errorf("Error al reservar el inodo del archivo: %v", err)
+	}
+
+	// Agregar el archivo a las entradas de su carpeta padre
+	err = sb.addEntry(file, parentIndex, name, newInodeIndex)
+	if err != nil {
+		return fmt.Errorf("Error al agregar el archivo '%s': %v", name, err)
+	}
+
+	// Crear el inodo del archivo
+	fileInode := &Inode{
+		I_uid:   1,
+		I_gid:   1,
+		I_size:  int32(size),
+		I_atime: float32(time.Now().Unix()),
+		I_ctime: float32(time.Now().Unix()),
+		I_mtime: float32(time.Now().Unix()),
+		I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_type:  [1]byte{'1'},
+		I_perm:  [3]byte{'6', '6', '4'},
+	}
+
+	// Reservar los bloques del archivo consecutivos según el ajuste de la partición
+	blockIndexes, err := sb.AllocateBlockRange(file, int32(len(cont)), fit)
+	if err != nil {
+		return fmt.Errorf("Error al reservar bloques de archivo: %v", err)
+	}
+
+	// Apuntar el inodo a los bloques, los que no caben en los directos van en bloques indirectos
+	err = sb.setFileBlocks(file, fileInode, blockIndexes)
+	if err != nil {
+		return fmt.Errorf("Error al asignar los bloques del archivo: %v", err)
+	}
 
-		// Crear un nuevo bloque de carpeta
-		block := &FolderBlock{}
+	// Crear los bloques del archivo
+	for i, newBlockIndex := range blockIndexes {
+		// Crear el bloque del archivo
+		fileBlock := sb.NewFileBlock()
+		copy(fileBlock.B_content, cont[i])
 
-		// Deserializar el bloque
-		err := block.Decode(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size))) // posición del bloque
+		// Serializar el bloque
+		err = fileBlock.Encode(file, sb.CalculateBlockOffset(newBlockIndex))
 		if err != nil {
-			return fmt.Errorf("Error al deserializar bloque %d: %v", blockIndex, err)
+			return fmt.Errorf("Error al serializar bloque de archivo: %v", err)
 		}
 
-		// Iterar sobre cada contenido del bloque, desde el index 2 porque los primeros dos son . y ..
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			// Obtener el contenido del bloque
-			content := block.B_content[indexContent]
-
-			// Si las carpetas padre no están vacías, debemos buscar la carpeta padre más cercana
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					fmt.Printf("No se encontró carpeta padre en el inodo %d, saliendo.\n", inodeIndex) // Depuración
-					break
-				}
-
-				// Obtener la carpeta padre más cercana
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return err
-				}
-
-				// Convertir B_name a string y eliminar los caracteres nulos
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				// Convertir parentDir a string y eliminar los caracteres nulos
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-
-				// Si el nombre del contenido coincide con el nombre de la carpeta padre
-				if strings.EqualFold(contentName, parentDirName) {
-					fmt.Printf("Encontrada carpeta padre '%s' en inodo %d\n", parentDirName, content.B_inodo) // Depuración
-					// Si son las mismas, entonces entramos al inodo que apunta el bloque
-					err := sb.createFileInInode(file, content.B_inodo, utils.RemoveElement(parentsDir, 0), destFile, fileSize, fileContent)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-			} else {
-				// Si el apuntador al inodo está ocupado, continuar con el siguiente
-				if content.B_inodo != -1 {
-					fmt.Printf("El inodo %d ya está ocupado, continuando.\n", content.B_inodo) // Depuración
-					continue
-				}
-
-				// Actualizar el contenido del bloque
-				copy(content.B_name[:], []byte(destFile))
-				content.B_inodo = sb.S_inodes_count
-
-				// Actualizar el bloque
-				block.B_content[indexContent] = content
-
-				// Serializar el bloque
-				err = block.Encode(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return fmt.Errorf("Error al serializar bloque %d: %v", blockIndex, err)
-				}
-
-				fmt.Printf("Bloque actualizado para el archivo '%s' en el inodo %d\n", destFile, sb.S_inodes_count) // Depuración
-
-				// Crear el inodo del archivo
-				fileInode := &Inode{
-					I_uid:   1,
-					I_gid:   1,
-					I_size:  int32(fileSize),
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'1'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-
-				// Crear los bloques del archivo
-				for i := 0; i < len(fileContent); i++ {
-					fileInode.I_block[i] = sb.S_blocks_count
-
-					// Crear el bloque del archivo
-					fileBlock := &FileBlock{
-						B_content: [64]byte{},
-					}
-					copy(fileBlock.B_content[:], fileContent[i])
-
-					// Serializar el bloque
-					err = fileBlock.Encode(file, int64(sb.S_first_blo))
-					if err != nil {
-						return fmt.Errorf("Error al serializar bloque de archivo: %v", err)
-					}
-
-					fmt.Printf("Bloque de archivo '%s' serializado correctamente.\n", destFile) // Depuración
-
-					// Actualizar el bitmap de bloques
-					err = sb.UpdateBitmapBlock(file, sb.S_blocks_count, true)
-					if err != nil {
-						return fmt.Errorf("Error al actualizar bitmap de bloque: %v", err)
-					}
-
-					// Actualizar el superbloque
-					sb.UpdateSuperblockAfterBlockAllocation()
-				}
-
-				// Serializar el inodo del archivo
-				err = fileInode.Encode(file, int64(sb.S_first_ino))
-				if err != nil {
-					return fmt.Errorf("Error al serializar inodo del archivo: %v", err)
-				}
-
-				fmt.Printf("Inodo del archivo '%s' serializado correctamente.\n", destFile) // Depuración
-
-				// Actualizar el bitmap de inodos
-				err = sb.UpdateBitmapInode(file, sb.S_inodes_count, true)
-				if err != nil {
-					return fmt.Errorf("Error al actualizar bitmap de inodo: %v", err)
-				}
-
-				// Actualizar el superbloque
-				sb.UpdateSuperblockAfterInodeAllocation()
-
-				fmt.Printf("Archivo '%s' creado correctamente en el inodo %d.\n", destFile, sb.S_inodes_count) // Depuración
-
-				return nil
-			}
-		}
+		fmt.Printf("Bloque de archivo '%s' serializado correctamente.\n", name) // Depuración
 	}
+
+	// Serializar el inodo del archivo
+	err = fileInode.Encode(file, sb.CalculateInodeOffset(newInodeIndex))
+	if err != nil {
+		return fmt.Errorf("Error al serializar inodo del archivo: %v", err)
+	}
+
+	fmt.Printf("Archivo '%s' creado correctamente en el inodo %d.\n", name, newInodeIndex) // Depuración
 	return nil
 }
 
-// CreateFile crea un archivo en el sistema de archivos
-func (sb *Superblock) CreateFile(file *os.File, parentsDir []string, destFile string, size int, cont []string) error {
-	fmt.Printf("Creando archivo '%s' con tamaño %d\n", destFile, size) // Depuración
+// MaxFileBlocks es la cantidad máxima de bloques de datos de un inodo
+// 12 directos, y los que alcanzan los bloques indirectos simple, doble y triple
+const MaxFileBlocks = 12 + PointersPerBlock + PointersPerBlock*PointersPerBlock + PointersPerBlock*PointersPerBlock*PointersPerBlock
 
-	// Si parentsDir está vacío, solo trabajar con el primer inodo que sería el raíz "/"
-	if len(parentsDir) == 0 {
-		return sb.createFileInInode(file, 0, parentsDir, destFile, size, cont)
+// setFileBlocks apunta el inodo a los bloques de datos en orden
+// Los primeros 12 van en los apuntadores directos y el resto en bloques de apuntadores que se reservan aquí
+func (sb *Superblock) setFileBlocks(file *os.File, inode *Inode, blocks []int32) error {
+	if len(blocks) > MaxFileBlocks {
+		return fmt.Errorf("el inodo no puede apuntar a %d bloques, el máximo es %d", len(blocks), MaxFileBlocks)
 	}
 
-	// Iterar sobre cada inodo ya que se necesita buscar el inodo padre
-	for i := int32(0); i < sb.S_inodes_count; i++ {
-		err := sb.createFileInInode(file, i, parentsDir, destFile, size, cont)
+	direct := copy(inode.I_block[:12], blocks)
+	blocks = blocks[direct:]
+
+	// Indirecto simple, doble y triple
+	for level := 1; level <= 3 && len(blocks) > 0; level++ {
+		pointerIndex, rest, err := sb.writePointerBlock(file, level, blocks)
 		if err != nil {
 			return err
 		}
+		inode.I_block[11+level] = pointerIndex
+		blocks = rest
 	}
-
-	fmt.Printf("Archivo '%s' creado exitosamente.\n", destFile) // Depuración
 	return nil
 }
+
+// writePointerBlock reserva un bloque de apuntadores del nivel indicado y apunta a tantos bloques como le quepan
+// Devuelve el bloque de apuntadores y los bloques que quedaron sin asignar
+func (sb *Superblock) writePointerBlock(file *os.File, level int, blocks []int32) (int32, []int32, error) {
+	pointerIndex, err := sb.AllocateBlock(file)
+	if err != nil {
+		return -1, nil, fmt.Errorf("error al reservar un bloque de apuntadores: %w", err)
+	}
+
+	pointerBlock := NewPointerBlock()
+	for i := range pointerBlock.B_pointers {
+		if len(blocks) == 0 {
+			break
+		}
+		if level == 1 {
+			pointerBlock.B_pointers[i] = blocks[0]
+			blocks = blocks[1:]
+			continue
+		}
+
+		// En los niveles doble y triple cada apuntador es otro bloque de apuntadores
+		childIndex, rest, err := sb.writePointerBlock(file, level-1, blocks)
+		if err != nil {
+			return -1, nil, err
+		}
+		pointerBlock.B_pointers[i] = childIndex
+		blocks = rest
+	}
+
+	err = pointerBlock.Encode(file, sb.CalculateBlockOffset(pointerIndex))
+	if err != nil {
+		return -1, nil, fmt.Errorf("error al serializar el bloque de apuntadores %d: %w", pointerIndex, err)
+	}
+	fmt.Printf("Bloque de apuntadores %d (nivel %d) serializado\n", pointerIndex, level) // Depuración
+	return pointerIndex, blocks, nil
+}
+
+// FileBlocks devuelve en orden los bloques de datos del inodo, incluidos los que están en bloques indirectos
+func (sb *Superblock) FileBlocks(file *os.File, inode *Inode) ([]int32, error) {
+	var blocks []int32
+	for _, blockIndex := range inode.I_block[:12] {
+		if blockIndex != -1 {
+			blocks = append(blocks, blockIndex)
+		}
+	}
+
+	for level := 1; level <= 3; level++ {
+		pointerIndex := inode.I_block[11+level]
+		if pointerIndex == -1 {
+			continue
+		}
+		var err error
+		blocks, err = sb.appendPointedBlocks(file, pointerIndex, level, blocks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// appendPointedBlocks agrega a blocks los bloques de datos a los que llega el bloque de apuntadores
+func (sb *Superblock) appendPointedBlocks(file *os.File, pointerIndex int32, level int, blocks []int32) ([]int32, error) {
+	pointerBlock := &PointerBlock{}
+	err := pointerBlock.Decode(file, sb.CalculateBlockOffset(pointerIndex))
+	if err != nil {
+		return nil, fmt.Errorf("error al deserializar el bloque de apuntadores %d: %w", pointerIndex, err)
+	}
+
+	for _, pointer := range pointerBlock.B_pointers {
+		// El bloque 0 siempre pertenece a la raíz, por lo que 0 y -1 indican apuntadores libres
+		if pointer <= 0 {
+			continue
+		}
+		if level == 1 {
+			blocks = append(blocks, pointer)
+			continue
+		}
+		blocks, err = sb.appendPointedBlocks(file, pointer, level-1, blocks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
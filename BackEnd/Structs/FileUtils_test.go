@@ -0,0 +1,35 @@
+package structs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCreateFileTooLarge(t *testing.T) {
+	file, sb := newTestDisk(t, 32)
+	freeInodes, freeBlocks := sb.S_free_inodes_count, sb.S_free_blocks_count
+	root := readInode(t, file, sb, RootInode)
+
+	// Un archivo que no cabe en el inodo se rechaza sin reservar el inodo ni agregar la entrada
+	cont := make([]string, MaxFileBlocks+1)
+	err := sb.CreateFile(file, "/grande.txt", len(cont)*DefaultBlockSize, cont, FirstFit)
+	if err == nil {
+		t.Fatal("se creó un archivo con más bloques de los que caben en un inodo")
+	}
+	if sb.S_free_inodes_count != freeInodes || sb.S_free_blocks_count != freeBlocks {
+		t.Fatalf("se reservaron %d inodos y %d bloques", freeInodes-sb.S_free_inodes_count, freeBlocks-sb.S_free_blocks_count)
+	}
+	if _, err := sb.ResolvePath(file, "/grande.txt"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("quedó la entrada del archivo en la raíz: %v", err)
+	}
+	if *readInode(t, file, sb, RootInode) != *root {
+		t.Fatal("el inodo de la raíz cambió")
+	}
+	result, err := sb.Check(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Problems) != 0 {
+		t.Fatalf("quedaron problemas en el sistema de archivos: %+v", result.Problems)
+	}
+}
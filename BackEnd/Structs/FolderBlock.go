@@ -4,9 +4,11 @@ import (
 	"backend/utils" // Asegúrate de ajustar el path del package "utils"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // FolderBlock representa un bloque de carpeta con 4 contenidos
+// Tiene 4 entradas sin importar el tamaño de bloque de la partición, ocupa MinBlockSize bytes
 type FolderBlock struct {
 	B_content [4]FolderContent // 4 * 16 = 64 bytes
 	// Total: 64 bytes
@@ -19,6 +21,20 @@ type FolderContent struct {
 	// Total: 16 bytes
 }
 
+// NewFolderBlock crea un bloque de carpeta con todas sus entradas libres
+func NewFolderBlock() *FolderBlock {
+	block := &FolderBlock{}
+	for i := range block.B_content {
+		block.B_content[i] = FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}
+	}
+	return block
+}
+
+// Name devuelve el nombre de la entrada sin los caracteres nulos de relleno
+func (fc *FolderContent) Name() string {
+	return strings.TrimRight(string(fc.B_name[:]), "\x00")
+}
+
 // Encode serializa la estructura FolderBlock en un archivo binario en la posición especificada
 func (fb *FolderBlock) Encode(file *os.File, offset int64) error {
 	// Utilizamos la función WriteToFile del paquete utils
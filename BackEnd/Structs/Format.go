@@ -0,0 +1,109 @@
+package structs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// DefaultInodeRatio es la cantidad de bloques por inodo cuando mkfs no recibe -inoderatio
+const DefaultInodeRatio = 3
+
+// CalculateInodeCount calcula la cantidad de inodos (n) que caben en una partición de partSize bytes
+func CalculateInodeCount(partSize int32, bs int32, ratio int32, fs int32) int32 {
+	/*
+		numerador = (partition_montada.size - sizeof(Structs::Superblock)
+		denrominador base = (1 + ratio + sizeof(Structs::Inodes) + ratio * bs)
+		en ext3 se suma sizeof(Structs::Journal), hay una entrada de journal por inodo
+		n = floor(numerador / denrominador)
+		Con los valores por defecto (bs=64, ratio=3) queda 4 + sizeof(Inode) + 3 * 64
+	*/
+
+	numerator := int(partSize) - binary.Size(Superblock{})
+	denominator := 1 + int(ratio) + binary.Size(Inode{}) + int(ratio)*int(bs)
+	if fs == 3 {
+		denominator += binary.Size(Journal{})
+	}
+	n := math.Floor(float64(numerator) / float64(denominator))
+
+	return int32(n)
+}
+
+// NewSuperblock crea el superbloque de una partición que empieza en partStart con n inodos
+// Las estructuras quedan en orden: superbloque, journal (solo ext3), bitmap de inodos, bitmap de bloques, inodos y bloques
+func NewSuperblock(partStart int32, n int32, bs int32, ratio int32, fs int32) *Superblock {
+	// Calcular punteros de las estructuras
+	// Journal, en ext3 ocupa n entradas entre el superbloque y el bitmap de inodos
+	journal_size := int32(0)
+	if fs == 3 {
+		journal_size = int32(binary.Size(Journal{})) * n
+	}
+	// Bitmaps
+	bm_inode_start := partStart + int32(binary.Size(Superblock{})) + journal_size
+	bm_block_start := bm_inode_start + n // n indica la cantidad de inodos, solo la cantidad para ser representada en un bitmap
+	// Inodos
+	inode_start := bm_block_start + (ratio * n) // ratio*n indica la cantidad de bloques, por defecto 3 porque se tienen 3 tipos de bloques
+	// Bloques
+	block_start := inode_start + (int32(binary.Size(Inode{})) * n) // n indica la cantidad de inodos, solo que aquí indica la cantidad de estructuras Inode
+
+	// Crear un nuevo superbloque
+	superBlock := &Superblock{
+		S_filesystem_type:   fs,
+		S_inodes_count:      int32(n),
+		S_blocks_count:      int32(n * ratio),
+		S_free_inodes_count: int32(n),
+		S_free_blocks_count: int32(n * ratio),
+		S_mtime:             float64(time.Now().Unix()),
+		S_umtime:            float64(time.Now().Unix()),
+		S_mnt_count:         1,
+		S_magic:             SuperblockMagic,
+		S_inode_size:        int32(binary.Size(Inode{})),
+		S_block_size:        bs,
+		S_first_ino:         inode_start,
+		S_first_blo:         block_start,
+		S_bm_inode_start:    bm_inode_start,
+		S_bm_block_start:    bm_block_start,
+		S_inode_start:       inode_start,
+		S_block_start:       block_start,
+	}
+
+	// Por defecto solo el grupo root tiene privilegios de administración
+	// No puede fallar porque el bitmap de inodos empieza después del superbloque completo
+	_ = superBlock.SetAdminGroup("root", int64(partStart))
+	return superBlock
+}
+
+// End devuelve la posición donde termina el último bloque de la partición
+func (sb *Superblock) End() int64 {
+	return int64(sb.S_block_start) + int64(sb.S_blocks_count)*int64(sb.S_block_size)
+}
+
+// Format escribe las estructuras de un sistema de archivos vacío: el journal en ext3, los bitmaps,
+// la raíz con users.txt y el superbloque en offset
+func (sb *Superblock) Format(file *os.File, offset int64) error {
+	// En ext3 el journal queda vacío, también en el formateo fast
+	if sb.S_filesystem_type == 3 {
+		err := sb.CreateJournal(file)
+		if err != nil {
+			return fmt.Errorf("error creando el journal: %w", err)
+		}
+	}
+
+	err := sb.CreateBitMaps(file)
+	if err != nil {
+		return fmt.Errorf("error creando bitmaps: %w", err)
+	}
+
+	err = sb.CreateUsersFile(file)
+	if err != nil {
+		return fmt.Errorf("error creando el archivo users.txt: %w", err)
+	}
+
+	err = sb.Encode(file, offset)
+	if err != nil {
+		return fmt.Errorf("error al escribir el superbloque en la partición: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package structs
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestNewSuperblockLayout(t *testing.T) {
+	const partSize = 1024 * 1024
+
+	tests := []struct {
+		name      string
+		bs, ratio int32
+		fs        int32
+	}{
+		{name: "ext2 por defecto", bs: DefaultBlockSize, ratio: DefaultInodeRatio, fs: 2},
+		{name: "ext3 por defecto", bs: DefaultBlockSize, ratio: DefaultInodeRatio, fs: 3},
+		{name: "ext2 bloques de 1024", bs: 1024, ratio: 2, fs: 2},
+		{name: "ext3 un bloque por inodo", bs: 128, ratio: 1, fs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := CalculateInodeCount(partSize, tt.bs, tt.ratio, tt.fs)
+			if n <= 0 {
+				t.Fatalf("CalculateInodeCount() = %d", n)
+			}
+			sb := NewSuperblock(testPartitionStart, n, tt.bs, tt.ratio, tt.fs)
+
+			// n es la mayor cantidad de inodos que cabe en la partición
+			if sb.End() > testPartitionStart+partSize {
+				t.Fatalf("con %d inodos la partición termina en %d, después de %d", n, sb.End(), testPartitionStart+partSize)
+			}
+			if next := NewSuperblock(testPartitionStart, n+1, tt.bs, tt.ratio, tt.fs); next.End() <= testPartitionStart+partSize {
+				t.Fatalf("caben %d inodos y CalculateInodeCount() devolvió %d", n+1, n)
+			}
+
+			// Las estructuras van una después de otra sin superponerse
+			journalStart := int64(testPartitionStart + binary.Size(Superblock{}))
+			if tt.fs == 3 && sb.JournalStart() != journalStart {
+				t.Fatalf("JournalStart() = %d, se esperaba %d", sb.JournalStart(), journalStart)
+			}
+			sections := []int64{
+				journalStart,
+				int64(sb.S_bm_inode_start),
+				int64(sb.S_bm_block_start),
+				int64(sb.S_inode_start),
+				int64(sb.S_block_start),
+				sb.End(),
+			}
+			sizes := []int64{
+				int64(sb.S_bm_inode_start) - journalStart,
+				int64(n),
+				int64(sb.S_blocks_count),
+				int64(n) * int64(binary.Size(Inode{})),
+				int64(sb.S_blocks_count) * int64(tt.bs),
+			}
+			if tt.fs == 3 && sizes[0] != int64(n)*int64(binary.Size(Journal{})) {
+				t.Fatalf("el journal ocupa %d bytes, se esperaban %d entradas", sizes[0], n)
+			}
+			if tt.fs == 2 && sizes[0] != 0 {
+				t.Fatalf("ext2 reservó %d bytes de journal", sizes[0])
+			}
+			for i, size := range sizes {
+				if sections[i]+size != sections[i+1] {
+					t.Fatalf("la sección %d termina en %d y la siguiente empieza en %d", i, sections[i]+size, sections[i+1])
+				}
+			}
+			if sb.S_blocks_count != n*tt.ratio || sb.GetAdminGroup() != "root" {
+				t.Fatalf("superbloque inesperado: %d bloques, grupo administrador %q", sb.S_blocks_count, sb.GetAdminGroup())
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, fs := range []int32{2, 3} {
+		file, sb := newTestDiskFS(t, 32, fs)
+
+		// El superbloque escrito es el mismo que quedó en memoria y el sistema de archivos está consistente
+		decoded := &Superblock{}
+		if err := decoded.Decode(file, testPartitionStart); err != nil {
+			t.Fatal(err)
+		}
+		if err := decoded.Validate(); err != nil {
+			t.Fatalf("ext%d: %v", fs, err)
+		}
+		if *decoded != *sb {
+			t.Fatalf("ext%d: el superbloque en el disco no coincide con el de memoria", fs)
+		}
+		result, err := decoded.Check(file, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Problems) != 0 {
+			t.Fatalf("ext%d: el formateo dejó problemas: %+v", fs, result.Problems)
+		}
+		if decoded.S_free_inodes_count != 30 || decoded.S_free_blocks_count != 94 {
+			t.Fatalf("ext%d: quedaron %d inodos y %d bloques libres", fs, decoded.S_free_inodes_count, decoded.S_free_blocks_count)
+		}
+	}
+}
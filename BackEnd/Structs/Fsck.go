@@ -0,0 +1,463 @@
+package structs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FsckProblem describe una inconsistencia encontrada al verificar el sistema de archivos
+type FsckProblem struct {
+	Description string // Descripción del problema
+	Repaired    bool   // Indica si el problema fue reparado
+}
+
+// FsckResult contiene el resultado de la verificación del sistema de archivos
+type FsckResult struct {
+	Problems    []FsckProblem // Problemas encontrados en el orden en que se detectaron
+	TotalInodes int32         // Cantidad total de inodos de la partición
+	TotalBlocks int32         // Cantidad total de bloques de la partición
+	UsedInodes  int32         // Inodos alcanzables desde la raíz
+	UsedBlocks  int32         // Bloques alcanzables desde la raíz
+
+	superblockProblems []int // Problemas de los contadores, se reparan cuando el llamador escribe el superbloque
+}
+
+// SuperblockWritten marca como reparados los problemas de los contadores, se llama después de serializar el superbloque
+func (r *FsckResult) SuperblockWritten() {
+	for _, problem := range r.superblockProblems {
+		r.Problems[problem].Repaired = true
+	}
+	r.superblockProblems = nil
+}
+
+// RepairedCount devuelve la cantidad de problemas cuya corrección se escribió en el disco
+func (r *FsckResult) RepairedCount() int {
+	count := 0
+	for _, problem := range r.Problems {
+		if problem.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// fsChecker guarda el estado de la verificación mientras se recorre el árbol desde la raíz
+type fsChecker struct {
+	sb              *Superblock
+	file            *os.File
+	repair          bool
+	inodeBitmap     *Bitmap // Copia en memoria del bitmap de inodos
+	blockBitmap     *Bitmap // Copia en memoria del bitmap de bloques
+	reachableInodes []bool
+	reachableBlocks []bool
+	result          *FsckResult
+}
+
+// TotalInodes devuelve la cantidad total de inodos según la distribución de la partición
+// Se calcula a partir del tamaño de la tabla de inodos para validar S_inodes_count sin depender de él
+func (sb *Superblock) TotalInodes() int32 {
+	return (sb.S_block_start - sb.S_inode_start) / sb.S_inode_size
+}
+
+// TotalBlocks devuelve la cantidad total de bloques según la distribución de la partición
+// El espacio reservado para el bitmap de bloques tiene un byte por cada bloque
+func (sb *Superblock) TotalBlocks() int32 {
+	return sb.S_inode_start - sb.S_bm_block_start
+}
+
+// Check verifica la consistencia del sistema de archivos
+// Recorre el árbol desde la raíz y compara los inodos y bloques alcanzables con los bitmaps y los contadores del superbloque
+// Si repair es true corrige los problemas encontrados, el llamador debe serializar el superbloque después
+func (sb *Superblock) Check(file *os.File, repair bool) (*FsckResult, error) {
+	c := &fsChecker{
+		sb:     sb,
+		file:   file,
+		repair: repair,
+		result: &FsckResult{TotalInodes: sb.TotalInodes(), TotalBlocks: sb.TotalBlocks()},
+	}
+	c.reachableInodes = make([]bool, c.result.TotalInodes)
+	c.reachableBlocks = make([]bool, c.result.TotalBlocks)
+
+	// Leer los bitmaps completos en memoria
+	var err error
+	c.inodeBitmap, err = loadBitmap(file, sb.S_bm_inode_start, c.result.TotalInodes)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el bitmap de inodos: %w", err)
+	}
+	c.blockBitmap, err = loadBitmap(file, sb.S_bm_block_start, c.result.TotalBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el bitmap de bloques: %w", err)
+	}
+
+	// La raíz debe ser una carpeta, si no lo es no hay árbol que recorrer
+	root := &Inode{}
+	err = root.Decode(file, sb.CalculateInodeOffset(0))
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el inodo raíz: %w", err)
+	}
+	if root.I_type[0] != '0' {
+		return nil, fmt.Errorf("el inodo raíz no es una carpeta, el sistema de archivos no se puede reparar")
+	}
+
+	// Recorrer el árbol desde la raíz, el padre de la raíz es ella misma
+	err = c.checkInode(0, 0, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	// Comparar los bitmaps con lo alcanzable desde la raíz
+	err = c.checkBitmaps()
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar los contadores del superbloque
+	err = c.checkCounters()
+	if err != nil {
+		return nil, err
+	}
+	return c.result, nil
+}
+
+// report agrega un problema al resultado y devuelve su posición
+// El problema queda sin reparar hasta que markRepaired lo marca, después de escribir la corrección
+func (c *fsChecker) report(format string, args ...interface{}) int {
+	description := fmt.Sprintf(format, args...)
+	fmt.Println("fsck:", description) // Depuración
+	c.result.Problems = append(c.result.Problems, FsckProblem{Description: description})
+	return len(c.result.Problems) - 1
+}
+
+// markRepaired marca como reparados los problemas cuya corrección ya se escribió
+func (c *fsChecker) markRepaired(problems ...int) {
+	for _, problem := range problems {
+		c.result.Problems[problem].Repaired = true
+	}
+}
+
+// checkInode marca un inodo como alcanzable y verifica sus bloques
+func (c *fsChecker) checkInode(inodeIndex int32, parentIndex int32, path string) error {
+	c.reachableInodes[inodeIndex] = true
+
+	inode := &Inode{}
+	inodeOffset := c.sb.CalculateInodeOffset(inodeIndex)
+	err := inode.Decode(c.file, inodeOffset)
+	if err != nil {
+		return fmt.Errorf("error al leer el inodo %d: %w", inodeIndex, err)
+	}
+	isFolder := inode.I_type[0] == '0'
+
+	// Estado de la carpeta compartido entre todos sus bloques
+	dir := &fsckDir{index: inodeIndex, parent: parentIndex, path: path, names: map[string]bool{".": true, "..": true}}
+	var problems []int // Problemas que se corrigen al escribir el inodo
+
+	// Bloques directos, el primero de una carpeta contiene . y ..
+	for i := 0; i < 12; i++ {
+		blockIndex := inode.I_block[i]
+		if blockIndex == -1 {
+			continue
+		}
+		if problem, ok := c.claimBlock(blockIndex, fmt.Sprintf("el inodo %d (%s)", inodeIndex, path)); !ok {
+			inode.I_block[i] = -1
+			problems = append(problems, problem)
+			continue
+		}
+		if isFolder {
+			err = c.checkFolderBlock(dir, blockIndex, i == 0)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Bloques indirectos simple, doble y triple
+	for level := 1; level <= 3; level++ {
+		blockIndex := inode.I_block[11+level]
+		if blockIndex == -1 {
+			continue
+		}
+		if problem, ok := c.claimBlock(blockIndex, fmt.Sprintf("el inodo %d (%s)", inodeIndex, path)); !ok {
+			inode.I_block[11+level] = -1
+			problems = append(problems, problem)
+			continue
+		}
+		err = c.checkPointerBlock(dir, blockIndex, level, isFolder)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(problems) > 0 && c.repair {
+		err = inode.Encode(c.file, inodeOffset)
+		if err != nil {
+			return fmt.Errorf("error al escribir el inodo %d: %w", inodeIndex, err)
+		}
+		c.markRepaired(problems...)
+	}
+	return nil
+}
+
+// claimBlock marca un bloque como alcanzable
+// Devuelve false si el apuntador está fuera de rango o el bloque ya pertenece a otro inodo, en ese caso el apuntador debe liberarse
+// El primer valor es el problema reportado, el llamador lo marca como reparado al escribir el apuntador liberado
+func (c *fsChecker) claimBlock(blockIndex int32, owner string) (int, bool) {
+	if blockIndex < 0 || blockIndex >= c.result.TotalBlocks {
+		return c.report("%s apunta al bloque %d, que está fuera de rango", owner, blockIndex), false
+	}
+	if c.reachableBlocks[blockIndex] {
+		return c.report("%s apunta al bloque %d, que ya pertenece a otro inodo", owner, blockIndex), false
+	}
+	c.reachableBlocks[blockIndex] = true
+	return -1, true
+}
+
+// checkPointerBlock verifica un bloque de apuntadores y los bloques a los que apunta
+func (c *fsChecker) checkPointerBlock(dir *fsckDir, blockIndex int32, level int, isFolder bool) error {
+	pointerBlock := &PointerBlock{}
+	blockOffset := int64(c.sb.S_block_start + blockIndex*c.sb.S_block_size)
+	err := pointerBlock.Decode(c.file, blockOffset)
+	if err != nil {
+		return fmt.Errorf("error al leer el bloque de apuntadores %d: %w", blockIndex, err)
+	}
+
+	var problems []int // Problemas que se corrigen al escribir el bloque de apuntadores
+	for i, pointer := range pointerBlock.B_pointers {
+		// El bloque 0 siempre pertenece a la raíz, por lo que 0 y -1 indican apuntadores libres
+		if pointer <= 0 {
+			continue
+		}
+		if problem, ok := c.claimBlock(pointer, fmt.Sprintf("el bloque de apuntadores %d", blockIndex)); !ok {
+			pointerBlock.B_pointers[i] = -1
+			problems = append(problems, problem)
+			continue
+		}
+
+		child := pointer
+		if level > 1 {
+			err = c.checkPointerBlock(dir, child, level-1, isFolder)
+		} else if isFolder {
+			err = c.checkFolderBlock(dir, child, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(problems) > 0 && c.repair {
+		err = pointerBlock.Encode(c.file, blockOffset)
+		if err != nil {
+			return fmt.Errorf("error al escribir el bloque de apuntadores %d: %w", blockIndex, err)
+		}
+		c.markRepaired(problems...)
+	}
+	return nil
+}
+
+// fsckDir guarda los datos de la carpeta que se está verificando
+type fsckDir struct {
+	index  int32           // Inodo de la carpeta
+	parent int32           // Inodo de la carpeta padre
+	path   string          // Ruta de la carpeta, usada en los mensajes
+	names  map[string]bool // Nombres ya vistos en la carpeta
+}
+
+// checkFolderBlock verifica las entradas de un bloque de carpeta y recorre los inodos hijos
+func (c *fsChecker) checkFolderBlock(dir *fsckDir, blockIndex int32, first bool) error {
+	block := &FolderBlock{}
+	blockOffset := int64(c.sb.S_block_start + blockIndex*c.sb.S_block_size)
+	err := block.Decode(c.file, blockOffset)
+	if err != nil {
+		return fmt.Errorf("error al leer el bloque de carpeta %d: %w", blockIndex, err)
+	}
+
+	var problems []int // Problemas que se corrigen al escribir el bloque de carpeta
+	var children []FolderContent
+	for i := range block.B_content {
+		content := &block.B_content[i]
+		name := strings.TrimRight(string(content.B_name[:]), "\x00")
+
+		// Las dos primeras entradas del primer bloque son . y ..
+		if first && i < 2 {
+			expectedName, expectedInode := ".", dir.index
+			if i == 1 {
+				expectedName, expectedInode = "..", dir.parent
+			}
+			if name != expectedName || content.B_inodo != expectedInode {
+				problems = append(problems, c.report("la entrada '%s' de %s apunta al inodo %d, debería ser '%s' al inodo %d", name, dir.path, content.B_inodo, expectedName, expectedInode))
+				content.B_name = [12]byte{}
+				copy(content.B_name[:], expectedName)
+				content.B_inodo = expectedInode
+			}
+			continue
+		}
+
+		if content.B_inodo == -1 {
+			continue
+		}
+
+		// La entrada debe apuntar a un inodo en uso de tipo carpeta o archivo
+		if problem := c.entryProblem(content.B_inodo); problem != "" {
+			problems = append(problems, c.report("la entrada '%s' de %s apunta al inodo %d, que %s", name, dir.path, content.B_inodo, problem))
+			clearEntry(content)
+			continue
+		}
+
+		// Cada inodo debe estar en una sola carpeta, también evita recorrer ciclos
+		if c.reachableInodes[content.B_inodo] {
+			problems = append(problems, c.report("la entrada '%s' de %s apunta al inodo %d, que ya está referenciado en otra entrada", name, dir.path, content.B_inodo))
+			clearEntry(content)
+			continue
+		}
+
+		// Los nombres no se pueden repetir dentro de la carpeta, el duplicado se renombra
+		if dir.names[name] {
+			newName := uniqueName(name, dir.names)
+			problems = append(problems, c.report("el nombre '%s' está duplicado en %s, el inodo %d se renombra a '%s'", name, dir.path, content.B_inodo, newName))
+			content.B_name = [12]byte{}
+			copy(content.B_name[:], newName)
+			name = newName
+		}
+		dir.names[name] = true
+
+		// Marcar el hijo antes de escribir el bloque para detectar referencias repetidas en el mismo bloque
+		c.reachableInodes[content.B_inodo] = true
+		children = append(children, *content)
+	}
+
+	if len(problems) > 0 && c.repair {
+		err = block.Encode(c.file, blockOffset)
+		if err != nil {
+			return fmt.Errorf("error al escribir el bloque de carpeta %d: %w", blockIndex, err)
+		}
+		c.markRepaired(problems...)
+	}
+
+	// Recorrer los hijos después de escribir el bloque
+	for _, child := range children {
+		childPath := strings.TrimSuffix(dir.path, "/") + "/" + strings.TrimRight(string(child.B_name[:]), "\x00")
+		err = c.checkInode(child.B_inodo, dir.index, childPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryProblem devuelve la razón por la que una entrada de carpeta no puede apuntar al inodo, vacío si es válido
+func (c *fsChecker) entryProblem(inodeIndex int32) string {
+	if inodeIndex < 0 || inodeIndex >= c.result.TotalInodes {
+		return "está fuera de rango"
+	}
+	if !c.inodeBitmap.IsSet(inodeIndex) {
+		return "está libre en el bitmap"
+	}
+
+	inode := &Inode{}
+	err := inode.Decode(c.file, c.sb.CalculateInodeOffset(inodeIndex))
+	if err != nil || (inode.I_type[0] != '0' && inode.I_type[0] != '1') {
+		return "no es una carpeta ni un archivo"
+	}
+	return ""
+}
+
+// clearEntry libera una entrada de carpeta
+func clearEntry(content *FolderContent) {
+	content.B_name = [12]byte{'-'}
+	content.B_inodo = -1
+}
+
+// uniqueName genera un nombre que no existe en la carpeta agregando un sufijo ~N
+func uniqueName(name string, names map[string]bool) string {
+	for n := 1; ; n++ {
+		suffix := fmt.Sprintf("~%d", n)
+		base := name
+		if len(base)+len(suffix) > 12 {
+			base = base[:12-len(suffix)]
+		}
+		if !names[base+suffix] {
+			return base + suffix
+		}
+	}
+}
+
+// checkBitmaps compara los bitmaps con los inodos y bloques alcanzables desde la raíz
+// Al reparar, cada bit corregido se escribe en el disco
+func (c *fsChecker) checkBitmaps() error {
+	for i := int32(0); i < c.result.TotalInodes; i++ {
+		used := c.inodeBitmap.IsSet(i)
+		problem := -1
+		if used && !c.reachableInodes[i] {
+			problem = c.report("el inodo %d está marcado como usado pero no es alcanzable desde la raíz (huérfano)", i)
+		} else if !used && c.reachableInodes[i] {
+			problem = c.report("el inodo %d es alcanzable desde la raíz pero está marcado como libre", i)
+		}
+		if problem != -1 && c.repair {
+			err := c.inodeBitmap.Set(c.file, i, c.reachableInodes[i])
+			if err != nil {
+				return fmt.Errorf("error al escribir el bitmap de inodos: %w", err)
+			}
+			c.markRepaired(problem)
+		}
+		if c.reachableInodes[i] {
+			c.result.UsedInodes++
+		}
+	}
+
+	for i := int32(0); i < c.result.TotalBlocks; i++ {
+		used := c.blockBitmap.IsSet(i)
+		problem := -1
+		if used && !c.reachableBlocks[i] {
+			problem = c.report("el bloque %d está marcado como usado pero ningún inodo lo referencia", i)
+		} else if !used && c.reachableBlocks[i] {
+			problem = c.report("el bloque %d está en uso pero marcado como libre", i)
+		}
+		if problem != -1 && c.repair {
+			err := c.blockBitmap.Set(c.file, i, c.reachableBlocks[i])
+			if err != nil {
+				return fmt.Errorf("error al escribir el bitmap de bloques: %w", err)
+			}
+			c.markRepaired(problem)
+		}
+		if c.reachableBlocks[i] {
+			c.result.UsedBlocks++
+		}
+	}
+	return nil
+}
+
+// checkCounters verifica los contadores del superbloque
+// Los totales deben coincidir con la distribución de la partición y los libres con lo alcanzable desde la raíz
+func (c *fsChecker) checkCounters() error {
+	freeInodes := c.result.TotalInodes - c.result.UsedInodes
+	freeBlocks := c.result.TotalBlocks - c.result.UsedBlocks
+
+	var problems []int
+	if c.sb.S_inodes_count != c.result.TotalInodes {
+		problems = append(problems, c.report("S_inodes_count es %d, la partición tiene %d inodos", c.sb.S_inodes_count, c.result.TotalInodes))
+	}
+	if c.sb.S_blocks_count != c.result.TotalBlocks {
+		problems = append(problems, c.report("S_blocks_count es %d, la partición tiene %d bloques", c.sb.S_blocks_count, c.result.TotalBlocks))
+	}
+	if c.sb.S_free_inodes_count != freeInodes {
+		problems = append(problems, c.report("S_free_inodes_count es %d, deberían ser %d inodos libres", c.sb.S_free_inodes_count, freeInodes))
+	}
+	if c.sb.S_free_blocks_count != freeBlocks {
+		problems = append(problems, c.report("S_free_blocks_count es %d, deberían ser %d bloques libres", c.sb.S_free_blocks_count, freeBlocks))
+	}
+
+	if !c.repair {
+		return nil
+	}
+
+	// Los libres se recalculan a partir de los bitmaps ya reparados
+	// Los contadores solo cambian en memoria, quedan reparados cuando el llamador escribe el superbloque
+	c.sb.S_inodes_count = c.result.TotalInodes
+	c.sb.S_blocks_count = c.result.TotalBlocks
+	err := c.sb.SyncFreeCounts(c.file)
+	if err != nil {
+		return err
+	}
+	c.result.superblockProblems = problems
+	return nil
+}
@@ -0,0 +1,149 @@
+package structs
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, file *os.File, sb *Superblock)
+		want    string // Parte de la descripción del problema que se debe detectar
+	}{
+		{
+			name: "inodo huérfano",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				if err := sb.UpdateBitmapInode(file, 20, true); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "el inodo 20 está marcado como usado pero no es alcanzable",
+		},
+		{
+			name: "bloque sin referencia",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				if err := sb.UpdateBitmapBlock(file, 30, true); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "el bloque 30 está marcado como usado pero ningún inodo lo referencia",
+		},
+		{
+			name: "entrada a un inodo libre",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				writeEntry(t, file, sb, RootInode, 3, "x", 25)
+			},
+			want: "la entrada 'x' de / apunta al inodo 25, que está libre en el bitmap",
+		},
+		{
+			name: "entrada .. incorrecta",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				writeEntry(t, file, sb, resolve(t, file, sb, "/a/b"), 1, "..", RootInode)
+			},
+			want: "debería ser '..'",
+		},
+		{
+			name: "nombre duplicado",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				writeEntry(t, file, sb, resolve(t, file, sb, "/a"), 3, "b", resolve(t, file, sb, "/a/f.txt"))
+			},
+			want: "el nombre 'b' está duplicado en /a",
+		},
+		{
+			name: "bloque compartido",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				index := resolve(t, file, sb, "/a/f.txt")
+				inode := readInode(t, file, sb, index)
+				inode.I_block[1] = readInode(t, file, sb, RootInode).I_block[0]
+				if err := inode.Encode(file, sb.CalculateInodeOffset(index)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "ya pertenece a otro inodo",
+		},
+		{
+			name: "contador de inodos libres",
+			corrupt: func(t *testing.T, file *os.File, sb *Superblock) {
+				sb.S_free_inodes_count++
+			},
+			want: "S_free_inodes_count",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, sb := newTestDisk(t, 32)
+			if err := sb.CreateFolders(file, "/a/b"); err != nil {
+				t.Fatal(err)
+			}
+			if err := sb.CreateFile(file, "/a/f.txt", 10, []string{"0123456789"}, 'F'); err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(t, file, sb)
+
+			// Sin -repair el problema se detecta pero no se marca como reparado
+			result, err := sb.Check(file, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !hasProblem(result, tt.want, false) {
+				t.Fatalf("no se detectó %q sin reparar: %+v", tt.want, result.Problems)
+			}
+
+			// Con -repair queda reparado cuando se escribe la corrección
+			result, err = sb.Check(file, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sb.Encode(file, testPartitionStart); err != nil {
+				t.Fatal(err)
+			}
+			result.SuperblockWritten()
+			if !hasProblem(result, tt.want, true) {
+				t.Fatalf("no se reparó %q: %+v", tt.want, result.Problems)
+			}
+			if result.RepairedCount() != len(result.Problems) {
+				t.Fatalf("se repararon %d de %d problemas", result.RepairedCount(), len(result.Problems))
+			}
+
+			// Después de reparar el sistema de archivos queda consistente
+			result, err = sb.Check(file, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Problems) != 0 {
+				t.Fatalf("quedaron problemas después de reparar: %+v", result.Problems)
+			}
+		})
+	}
+}
+
+func TestCheckCountersNotRepairedUntilWritten(t *testing.T) {
+	file, sb := newTestDisk(t, 32)
+	sb.S_free_blocks_count--
+
+	result, err := sb.Check(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// El superbloque solo cambió en memoria, el contador no está reparado hasta escribirlo
+	if !hasProblem(result, "S_free_blocks_count", false) {
+		t.Fatalf("el contador quedó reparado antes de escribir el superbloque: %+v", result.Problems)
+	}
+	result.SuperblockWritten()
+	if !hasProblem(result, "S_free_blocks_count", true) {
+		t.Fatalf("el contador no quedó reparado: %+v", result.Problems)
+	}
+}
+
+// hasProblem indica si el resultado tiene un problema que contiene want con el estado de reparación indicado
+func hasProblem(result *FsckResult, want string, repaired bool) bool {
+	for _, problem := range result.Problems {
+		if strings.Contains(problem.Description, want) && problem.Repaired == repaired {
+			return true
+		}
+	}
+	return false
+}
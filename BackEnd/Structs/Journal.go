@@ -0,0 +1,149 @@
+package structs
+
+import (
+	utilidades "backend/utils" // Importa el paquete utils
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Information representa la operación registrada en una entrada del journal
+type Information struct {
+	I_operation [10]byte // Nombre de la operación realizada (mkdir, mkfile, ...)
+	I_path      [32]byte // Ruta sobre la que se realizó la operación
+	I_content   [64]byte // Contenido asociado a la operación
+	I_date      float32  // Fecha en que se realizó la operación
+	// Total: 110 bytes
+}
+
+// Journal representa una entrada del área de journaling de ext3
+type Journal struct {
+	J_count   int32       // Número de la entrada dentro del journal
+	J_content Information // Información de la operación
+	// Total: 114 bytes
+}
+
+// Encode serializa la estructura Journal en un archivo binario en la posición especificada
+func (j *Journal) Encode(file *os.File, offset int64) error {
+	return utilidades.WriteToFile(file, offset, j)
+}
+
+// Decode deserializa la estructura Journal desde un archivo binario en la posición especificada
+func (j *Journal) Decode(file *os.File, offset int64) error {
+	return utilidades.ReadFromFile(file, offset, j)
+}
+
+// IsEmpty indica si la entrada del journal no tiene ninguna operación registrada
+func (j *Journal) IsEmpty() bool {
+	return j.GetOperation() == ""
+}
+
+// GetOperation devuelve la operación registrada sin caracteres nulos
+func (j *Journal) GetOperation() string {
+	return strings.TrimRight(string(j.J_content.I_operation[:]), "\x00")
+}
+
+// GetPath devuelve la ruta registrada sin caracteres nulos
+func (j *Journal) GetPath() string {
+	return strings.TrimRight(string(j.J_content.I_path[:]), "\x00")
+}
+
+// GetContent devuelve el contenido registrado sin caracteres nulos
+func (j *Journal) GetContent() string {
+	return strings.TrimRight(string(j.J_content.I_content[:]), "\x00")
+}
+
+// GetDate devuelve la fecha de la operación
+func (j *Journal) GetDate() time.Time {
+	return time.Unix(int64(j.J_content.I_date), 0)
+}
+
+// JournalStart calcula el inicio del área de journaling, ubicada entre el superbloque y el bitmap de inodos
+// mkfs reserva en ext3 una entrada de journal por cada inodo del sistema de archivos
+func (sb *Superblock) JournalStart() int64 {
+	return int64(sb.S_bm_inode_start) - int64(sb.S_inodes_count)*int64(binary.Size(Journal{}))
+}
+
+// CreateJournal deja vacías todas las entradas del journal, mkfs lo llama al formatear en ext3
+func (sb *Superblock) CreateJournal(file *os.File) error {
+	journalSize := int64(sb.S_inodes_count) * int64(binary.Size(Journal{}))
+	return utilidades.ZeroFill(file, sb.JournalStart(), journalSize)
+}
+
+// AppendJournal registra una operación en el journal, en particiones que no son ext3 no hace nada
+// Los campos que no caben se recortan, cuando el journal está lleno se reemplaza la entrada más antigua
+func (sb *Superblock) AppendJournal(file *os.File, operation string, path string, content string) error {
+	if sb.S_filesystem_type != 3 || sb.S_inodes_count <= 0 {
+		return nil
+	}
+
+	journalSize := int64(binary.Size(Journal{}))
+	start := sb.JournalStart()
+
+	// Las entradas se ocupan en orden, se usa la primera vacía o la de menor J_count si no hay vacías
+	slot := int64(-1)
+	oldest := int64(0)
+	var oldestCount, lastCount int32
+	for i := int64(0); i < int64(sb.S_inodes_count); i++ {
+		var entry Journal
+		err := entry.Decode(file, start+i*journalSize)
+		if err != nil {
+			return fmt.Errorf("error leyendo la entrada %d del journal: %w", i, err)
+		}
+		if entry.IsEmpty() {
+			slot = i
+			break
+		}
+		if entry.J_count > lastCount {
+			lastCount = entry.J_count
+		}
+		if i == 0 || entry.J_count < oldestCount {
+			oldest, oldestCount = i, entry.J_count
+		}
+	}
+	if slot == -1 {
+		slot = oldest
+	}
+
+	entry := Journal{J_count: lastCount + 1}
+	copy(entry.J_content.I_operation[:], operation)
+	copy(entry.J_content.I_path[:], path)
+	copy(entry.J_content.I_content[:], content)
+	entry.J_content.I_date = float32(time.Now().Unix())
+
+	err := entry.Encode(file, start+slot*journalSize)
+	if err != nil {
+		return fmt.Errorf("error escribiendo la entrada %d del journal: %w", slot, err)
+	}
+	return nil
+}
+
+// ReadJournalEntries lee las entradas ocupadas del journal hasta encontrar la primera vacía
+// Se devuelven ordenadas por J_count, cuando el journal se llena las más recientes reemplazan a las más antiguas
+func (sb *Superblock) ReadJournalEntries(file *os.File) ([]Journal, error) {
+	if sb.S_filesystem_type != 3 {
+		return nil, fmt.Errorf("la partición no tiene journaling, el sistema de archivos es ext%d", sb.S_filesystem_type)
+	}
+
+	journalSize := int64(binary.Size(Journal{}))
+	totalEntries := int64(sb.S_inodes_count)
+	start := sb.JournalStart()
+
+	var entries []Journal
+	for i := int64(0); i < totalEntries; i++ {
+		var entry Journal
+		err := entry.Decode(file, start+i*journalSize)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo la entrada %d del journal: %w", i, err)
+		}
+		if entry.IsEmpty() {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].J_count < entries[j].J_count })
+	return entries, nil
+}
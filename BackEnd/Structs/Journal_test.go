@@ -0,0 +1,55 @@
+package structs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppendJournal(t *testing.T) {
+	file, sb := newTestDiskFS(t, 4, 3)
+
+	// Los campos que no caben en la entrada se recortan
+	longPath := "/" + strings.Repeat("a", 40)
+	ops := []string{"mkdir", "mkfile", "mkgrp", "mkusr", "rmusr", "chgrp"}
+	for _, op := range ops {
+		if err := sb.AppendJournal(file, op, longPath, strings.Repeat("x", 100)); err != nil {
+			t.Fatalf("AppendJournal(%s): %v", op, err)
+		}
+	}
+
+	// El journal tiene una entrada por inodo, las dos primeras operaciones se reemplazaron
+	entries, err := sb.ReadJournalEntries(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("el journal tiene %d entradas, se esperaban 4", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.J_count != int32(i+3) || entry.GetOperation() != ops[i+2] {
+			t.Fatalf("entrada %d = %d %s, se esperaba %d %s", i, entry.J_count, entry.GetOperation(), i+3, ops[i+2])
+		}
+		if entry.GetPath() != longPath[:32] || len(entry.GetContent()) != 64 {
+			t.Fatalf("entrada %d sin recortar: %q %q", i, entry.GetPath(), entry.GetContent())
+		}
+	}
+}
+
+func TestAppendJournalExt2(t *testing.T) {
+	file, sb := newTestDiskFS(t, 4, 2)
+
+	// En ext2 no hay journal, el área que le correspondería es el superbloque y no se modifica
+	if err := sb.AppendJournal(file, "mkdir", "/a", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sb.ReadJournalEntries(file); err == nil {
+		t.Fatal("se leyó el journal de una partición ext2")
+	}
+	var stored Superblock
+	if err := stored.Decode(file, testPartitionStart); err != nil {
+		t.Fatal(err)
+	}
+	if stored != *sb {
+		t.Fatal("AppendJournal modificó el superbloque de la partición ext2")
+	}
+}
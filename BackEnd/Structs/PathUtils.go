@@ -0,0 +1,214 @@
+package structs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Todos los comandos y reportes resuelven los paths del sistema de archivos con este archivo
+// Los paths son absolutos y se recorren desde la raíz una sola vez, componente por componente
+// "." y ".." se resuelven con las entradas de la carpeta, la raíz es su propio padre
+// Los nombres distinguen mayúsculas de minúsculas y no se repiten dentro de una carpeta
+
+// RootInode es el inodo de la carpeta raíz, mkfs lo reserva primero
+const RootInode int32 = 0
+
+// MaxNameLength es la cantidad de bytes que caben en el nombre de una entrada de carpeta
+const MaxNameLength = 12
+
+// ErrPathNotFound indica que algún componente del path no existe
+var ErrPathNotFound = errors.New("no existe")
+
+// ErrPathExists indica que el último componente del path ya existe en su carpeta
+var ErrPathExists = errors.New("ya existe")
+
+// ValidateName verifica que el nombre se pueda guardar completo en una entrada de carpeta
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("el nombre no puede estar vacío")
+	case strings.Contains(name, "/"):
+		return fmt.Errorf("el nombre '%s' no puede contener '/'", name)
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("el nombre '%s' tiene %d bytes, el máximo es %d", name, len(name), MaxNameLength)
+	}
+	return nil
+}
+
+// SplitPath separa un path absoluto en sus componentes, las barras repetidas se ignoran
+func SplitPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("el path '%s' debe ser absoluto", path)
+	}
+
+	var components []string
+	for _, component := range strings.Split(path, "/") {
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+	return components, nil
+}
+
+// ResolvePath devuelve el inodo al que apunta el path
+func (sb *Superblock) ResolvePath(file *os.File, path string) (int32, error) {
+	components, err := SplitPath(path)
+	if err != nil {
+		return -1, err
+	}
+	return sb.walkPath(file, components, path)
+}
+
+// ResolveParent devuelve el inodo de la carpeta que contiene el último componente del path y el nombre de ese componente
+// Se usa para crear entradas, por eso el último componente debe ser un nombre válido que no exista en la carpeta
+func (sb *Superblock) ResolveParent(file *os.File, path string) (int32, string, error) {
+	components, err := SplitPath(path)
+	if err != nil {
+		return -1, "", err
+	}
+	if len(components) == 0 {
+		return -1, "", fmt.Errorf("el path '%s' no tiene un nombre", path)
+	}
+
+	name := components[len(components)-1]
+	if name == "." || name == ".." {
+		return -1, "", fmt.Errorf("el path '%s' debe terminar en un nombre", path)
+	}
+	err = ValidateName(name)
+	if err != nil {
+		return -1, "", err
+	}
+
+	parentIndex, err := sb.walkPath(file, components[:len(components)-1], path)
+	if err != nil {
+		return -1, "", err
+	}
+
+	// Verificar que el padre sea una carpeta antes de que se reserve algo para la nueva entrada
+	parent := &Inode{}
+	err = parent.Decode(file, sb.CalculateInodeOffset(parentIndex))
+	if err != nil {
+		return -1, "", fmt.Errorf("error al deserializar el inodo %d: %w", parentIndex, err)
+	}
+	if parent.I_type[0] != '0' {
+		return -1, "", fmt.Errorf("'/%s' no es una carpeta", strings.Join(components[:len(components)-1], "/"))
+	}
+
+	// Los nombres no se repiten dentro de una carpeta
+	existing, err := sb.LookupEntry(file, parentIndex, name)
+	if err != nil {
+		return -1, "", err
+	}
+	if existing != -1 {
+		return -1, "", fmt.Errorf("'/%s' %w", strings.Join(components, "/"), ErrPathExists)
+	}
+	return parentIndex, name, nil
+}
+
+// walkPath recorre los componentes desde la raíz y devuelve el inodo del último
+func (sb *Superblock) walkPath(file *os.File, components []string, path string) (int32, error) {
+	current := RootInode
+	for i, name := range components {
+		next, err := sb.LookupEntry(file, current, name)
+		if err != nil {
+			return -1, fmt.Errorf("'/%s' en el path '%s': %w", strings.Join(components[:i], "/"), path, err)
+		}
+		if next == -1 {
+			return -1, fmt.Errorf("'/%s' %w", strings.Join(components[:i+1], "/"), ErrPathNotFound)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// LookupEntry busca el nombre entre las entradas de la carpeta y devuelve el inodo al que apunta, -1 si no está
+// La comparación distingue mayúsculas de minúsculas
+func (sb *Superblock) LookupEntry(file *os.File, dirIndex int32, name string) (int32, error) {
+	dir := &Inode{}
+	err := dir.Decode(file, sb.CalculateInodeOffset(dirIndex))
+	if err != nil {
+		return -1, fmt.Errorf("error al deserializar el inodo %d: %w", dirIndex, err)
+	}
+	if dir.I_type[0] != '0' {
+		return -1, errors.New("no es una carpeta")
+	}
+
+	for _, blockIndex := range dir.I_block[:12] {
+		if blockIndex == -1 {
+			continue
+		}
+
+		block := &FolderBlock{}
+		err := block.Decode(file, sb.CalculateBlockOffset(blockIndex))
+		if err != nil {
+			return -1, fmt.Errorf("error al deserializar el bloque %d: %w", blockIndex, err)
+		}
+
+		for _, content := range block.B_content {
+			if content.B_inodo != -1 && content.Name() == name {
+				return content.B_inodo, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// addEntry agrega a la carpeta una entrada con el nombre que apunta al inodo child
+// Usa el primer espacio libre de sus bloques, si están llenos reserva un bloque nuevo para la carpeta
+func (sb *Superblock) addEntry(file *os.File, dirIndex int32, name string, child int32) error {
+	// copy recortaría en silencio un nombre que no cabe en B_name
+	err := ValidateName(name)
+	if err != nil {
+		return err
+	}
+
+	dir := &Inode{}
+	dirOffset := sb.CalculateInodeOffset(dirIndex)
+	err = dir.Decode(file, dirOffset)
+	if err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", dirIndex, err)
+	}
+	if dir.I_type[0] != '0' {
+		return fmt.Errorf("el inodo %d no es una carpeta", dirIndex)
+	}
+
+	entry := FolderContent{B_inodo: child}
+	copy(entry.B_name[:], name)
+
+	for i, blockIndex := range dir.I_block[:12] {
+		if blockIndex == -1 {
+			// Todos los bloques de la carpeta están llenos, se reserva uno nuevo en este apuntador
+			newBlockIndex, err := sb.AllocateBlock(file)
+			if err != nil {
+				return fmt.Errorf("error al reservar un bloque para la carpeta: %w", err)
+			}
+			block := NewFolderBlock()
+			block.B_content[0] = entry
+			err = block.Encode(file, sb.CalculateBlockOffset(newBlockIndex))
+			if err != nil {
+				return fmt.Errorf("error al serializar el bloque %d: %w", newBlockIndex, err)
+			}
+
+			dir.I_block[i] = newBlockIndex
+			dir.UpdateMtime()
+			return dir.Encode(file, dirOffset)
+		}
+
+		block := &FolderBlock{}
+		blockOffset := sb.CalculateBlockOffset(blockIndex)
+		err := block.Decode(file, blockOffset)
+		if err != nil {
+			return fmt.Errorf("error al deserializar el bloque %d: %w", blockIndex, err)
+		}
+		for j := range block.B_content {
+			if block.B_content[j].B_inodo == -1 {
+				block.B_content[j] = entry
+				fmt.Printf("Entrada '%s' agregada en el bloque %d, posición %d\n", name, blockIndex, j) // Depuración
+				return block.Encode(file, blockOffset)
+			}
+		}
+	}
+	return errors.New("la carpeta no tiene espacio para más entradas")
+}
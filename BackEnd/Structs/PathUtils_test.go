@@ -0,0 +1,196 @@
+package structs
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// newTestTree crea una partición de prueba con /a/b, /a/f.txt y /c
+func newTestTree(t *testing.T) (*os.File, *Superblock) {
+	t.Helper()
+	file, sb := newTestDisk(t, 32)
+	if err := sb.CreateFolders(file, "/a/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.CreateFolder(file, "/c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.CreateFile(file, "/a/f.txt", 3, []string{"abc"}, FirstFit); err != nil {
+		t.Fatal(err)
+	}
+	return file, sb
+}
+
+func TestResolvePath(t *testing.T) {
+	file, sb := newTestTree(t)
+	a := resolve(t, file, sb, "/a")
+	b := resolve(t, file, sb, "/a/b")
+	c := resolve(t, file, sb, "/c")
+
+	tests := []struct {
+		path    string
+		want    int32
+		wantErr error // nil si no se espera un error conocido
+		anyErr  bool  // Se espera un error sin sentinela
+	}{
+		{path: "/", want: RootInode},
+		{path: "/a/b", want: b},
+		{path: "//a///b/", want: b},
+		{path: "/a/.", want: a},
+		{path: "/a/./b/.", want: b},
+		{path: "/a/b/..", want: a},
+		{path: "/a/b/../../c", want: c},
+		{path: "/..", want: RootInode},
+		{path: "/../../a", want: a},
+		{path: "/a/x", wantErr: ErrPathNotFound},
+		{path: "/a/b/../x/b", wantErr: ErrPathNotFound},
+		{path: "/a/f.txt/b", anyErr: true},
+		{path: "a/b", anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := sb.ResolvePath(file, tt.path)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolvePath(%q) error = %v, se esperaba %v", tt.path, err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Fatalf("ResolvePath(%q) = %d, se esperaba un error", tt.path, got)
+				}
+			case err != nil:
+				t.Fatalf("ResolvePath(%q) error = %v", tt.path, err)
+			case got != tt.want:
+				t.Fatalf("ResolvePath(%q) = %d, se esperaba %d", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveParent(t *testing.T) {
+	file, sb := newTestTree(t)
+	a := resolve(t, file, sb, "/a")
+	b := resolve(t, file, sb, "/a/b")
+
+	tests := []struct {
+		path       string
+		wantParent int32
+		wantName   string
+		wantErr    error
+		anyErr     bool
+	}{
+		{path: "/nuevo", wantParent: RootInode, wantName: "nuevo"},
+		{path: "/a/b/nuevo", wantParent: b, wantName: "nuevo"},
+		{path: "/a/b/../nuevo", wantParent: a, wantName: "nuevo"},
+		{path: "/a/./nuevo.txt", wantParent: a, wantName: "nuevo.txt"},
+		{path: "/a/b", wantErr: ErrPathExists},
+		{path: "/a/b/..", anyErr: true},
+		{path: "/a/.", anyErr: true},
+		{path: "/", anyErr: true},
+		{path: "/x/nuevo", wantErr: ErrPathNotFound},
+		{path: "/a/f.txt/nuevo", anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			parent, name, err := sb.ResolveParent(file, tt.path)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveParent(%q) error = %v, se esperaba %v", tt.path, err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Fatalf("ResolveParent(%q) = %d, %q, se esperaba un error", tt.path, parent, name)
+				}
+			case err != nil:
+				t.Fatalf("ResolveParent(%q) error = %v", tt.path, err)
+			case parent != tt.wantParent || name != tt.wantName:
+				t.Fatalf("ResolveParent(%q) = %d, %q, se esperaba %d, %q", tt.path, parent, name, tt.wantParent, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLookupEntryCaseSensitive(t *testing.T) {
+	file, sb := newTestDisk(t, 32)
+	if err := sb.CreateFile(file, "/a.txt", 1, []string{"a"}, FirstFit); err != nil {
+		t.Fatal(err)
+	}
+	// Solo cambia la mayúscula, es un archivo distinto
+	if err := sb.CreateFile(file, "/A.txt", 1, []string{"A"}, FirstFit); err != nil {
+		t.Fatalf("no se pudo crear /A.txt junto a /a.txt: %v", err)
+	}
+	lower := resolve(t, file, sb, "/a.txt")
+	upper := resolve(t, file, sb, "/A.txt")
+	if lower == upper {
+		t.Fatalf("/a.txt y /A.txt apuntan al mismo inodo %d", lower)
+	}
+
+	tests := []struct {
+		path    string
+		want    int32
+		wantErr error
+	}{
+		{path: "/a.txt", want: lower},
+		{path: "/A.txt", want: upper},
+		{path: "/a.TXT", wantErr: ErrPathNotFound},
+		{path: "/a.tx", wantErr: ErrPathNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := sb.ResolvePath(file, tt.path)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && got != tt.want) {
+				t.Fatalf("ResolvePath(%q) = %d, %v, se esperaba %d, %v", tt.path, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// El mismo nombre con las mismas mayúsculas no se puede repetir
+	for _, path := range []string{"/a.txt", "/A.txt"} {
+		_, _, err := sb.ResolveParent(file, path)
+		if !errors.Is(err, ErrPathExists) {
+			t.Fatalf("ResolveParent(%q) error = %v, se esperaba %v", path, err, ErrPathExists)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "a"},
+		{name: "archivo.txt"},
+		{name: "doce_bytes12"},
+		{name: "trece_bytes13", wantErr: true},
+		{name: "", wantErr: true},
+		{name: "a/b", wantErr: true},
+		{name: "ñandú.txt"}, // 11 bytes, los nombres se miden en bytes
+		{name: "ñandúes.txt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateName(%q) error = %v, se esperaba error: %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+
+	// Un nombre que no cabe se rechaza antes de reservar nada, sin recortarlo
+	file, sb := newTestDisk(t, 32)
+	freeInodes := sb.S_free_inodes_count
+	if err := sb.CreateFolder(file, "/trece_bytes13"); err == nil {
+		t.Fatal("se creó una carpeta con un nombre de 13 bytes")
+	}
+	if sb.S_free_inodes_count != freeInodes {
+		t.Fatalf("se reservaron %d inodos para un nombre inválido", freeInodes-sb.S_free_inodes_count)
+	}
+	if _, err := sb.ResolvePath(file, "/trece_bytes1"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("el nombre se guardó recortado: %v", err)
+	}
+}
@@ -1,14 +1,29 @@
 package structs
 
 import (
+	utilidades "backend/utils"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"os"
 )
 
+// PointersPerBlock es la cantidad de apuntadores de un PointerBlock
+// Los 16 apuntadores de 4 bytes ocupan MinBlockSize bytes, la cantidad no cambia con el tamaño de bloque de la partición
+const PointersPerBlock = 16
+
 // PointerBlock : Estructura para guardar los bloques de apuntadores
 type PointerBlock struct {
-	B_pointers [16]int64 // Apuntadores a bloques de carpetas o datos
+	B_pointers [PointersPerBlock]int32 // Apuntadores a bloques de carpetas o datos
+}
+
+// NewPointerBlock crea un bloque de apuntadores con todos los apuntadores libres
+func NewPointerBlock() *PointerBlock {
+	block := &PointerBlock{}
+	for i := range block.B_pointers {
+		block.B_pointers[i] = -1
+	}
+	return block
 }
 
 // FindFreePointer busca el primer apuntador libre en un bloque de apuntadores y devuelve su índice
@@ -23,14 +38,15 @@ func (pb *PointerBlock) FindFreePointer() (int, error) {
 
 // Encode serializa el PointerBlock en el archivo en la posición dada
 func (pb *PointerBlock) Encode(file *os.File, offset int64) error {
-	// Mover el cursor del archivo a la posición deseada
-	_, err := file.Seek(offset, 0)
+	// Serializar la estructura PointerBlock, los apuntadores se guardan en BigEndian
+	var buffer bytes.Buffer
+	err := binary.Write(&buffer, binary.BigEndian, pb)
 	if err != nil {
-		return fmt.Errorf("error buscando la posición en el archivo: %w", err)
+		return fmt.Errorf("error escribiendo el PointerBlock: %w", err)
 	}
 
-	// Escribir la estructura PointerBlock en el archivo
-	err = binary.Write(file, binary.BigEndian, pb)
+	// Escribir el bloque en el archivo
+	err = utilidades.WriteBytes(file, offset, buffer.Bytes())
 	if err != nil {
 		return fmt.Errorf("error escribiendo el PointerBlock: %w", err)
 	}
@@ -39,14 +55,15 @@ func (pb *PointerBlock) Encode(file *os.File, offset int64) error {
 
 // Decode deserializa el PointerBlock desde el archivo en la posición dada
 func (pb *PointerBlock) Decode(file *os.File, offset int64) error {
-	// Mover el cursor del archivo a la posición deseada
-	_, err := file.Seek(offset, 0)
+	// Leer los bytes del bloque desde el archivo
+	buffer := make([]byte, binary.Size(pb))
+	err := utilidades.ReadBytes(file, offset, buffer)
 	if err != nil {
-		return fmt.Errorf("error buscando la posición en el archivo: %w", err)
+		return fmt.Errorf("error leyendo el PointerBlock: %w", err)
 	}
 
-	// Leer la estructura PointerBlock desde el archivo
-	err = binary.Read(file, binary.BigEndian, pb)
+	// Deserializar la estructura PointerBlock, los apuntadores se guardan en BigEndian
+	err = binary.Read(bytes.NewReader(buffer), binary.BigEndian, pb)
 	if err != nil {
 		return fmt.Errorf("error leyendo el PointerBlock: %w", err)
 	}
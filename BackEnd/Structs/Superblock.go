@@ -2,43 +2,139 @@ package structs
 
 import (
 	utilidades "backend/utils" // Importa el paquete utils
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 )
 
+// SuperblockMagic es el número mágico que identifica un superbloque ext2/ext3
+const SuperblockMagic int32 = 0xEF53
+
+// ErrNotFormatted indica que la partición no contiene un superbloque válido
+var ErrNotFormatted = errors.New("la partición no está formateada")
+
 type Superblock struct {
-	S_filesystem_type   int32   // Número que identifica el sistema de archivos usado
-	S_inodes_count      int32   // Número total de inodos creados
-	S_blocks_count      int32   // Número total de bloques creados
-	S_free_blocks_count int32   // Número de bloques libres
-	S_free_inodes_count int32   // Número de inodos libres
-	S_mtime             float64 // Última fecha en que el sistema fue montado
-	S_umtime            float64 // Última fecha en que el sistema fue desmontado
-	S_mnt_count         int32   // Número de veces que se ha montado el sistema
-	S_magic             int32   // Valor que identifica el sistema de archivos
-	S_inode_size        int32   // Tamaño de la estructura inodo
-	S_block_size        int32   // Tamaño de la estructura bloque
-	S_first_ino         int32   // Primer inodo libre
-	S_first_blo         int32   // Primer bloque libre
-	S_bm_inode_start    int32   // Inicio del bitmap de inodos
-	S_bm_block_start    int32   // Inicio del bitmap de bloques
-	S_inode_start       int32   // Inicio de la tabla de inodos
-	S_block_start       int32   // Inicio de la tabla de bloques
+	S_filesystem_type   int32    // Número que identifica el sistema de archivos usado
+	S_inodes_count      int32    // Número total de inodos, fijado al formatear
+	S_blocks_count      int32    // Número total de bloques, fijado al formatear
+	S_free_blocks_count int32    // Número de bloques libres
+	S_free_inodes_count int32    // Número de inodos libres
+	S_mtime             float64  // Última fecha en que el sistema fue montado
+	S_umtime            float64  // Última fecha en que el sistema fue desmontado
+	S_mnt_count         int32    // Número de veces que se ha montado el sistema
+	S_magic             int32    // Valor que identifica el sistema de archivos
+	S_inode_size        int32    // Tamaño de la estructura inodo
+	S_block_size        int32    // Tamaño de la estructura bloque
+	S_first_ino         int32    // Posición del primer inodo libre, -1 si no hay
+	S_first_blo         int32    // Posición del primer bloque libre, -1 si no hay
+	S_bm_inode_start    int32    // Inicio del bitmap de inodos
+	S_bm_block_start    int32    // Inicio del bitmap de bloques
+	S_inode_start       int32    // Inicio de la tabla de inodos
+	S_block_start       int32    // Inicio de la tabla de bloques
+	S_admin_group       [10]byte // Grupo cuyos miembros pueden administrar usuarios y grupos, solo existe si HasAdminGroup
+}
+
+// ErrNoAdminGroupField indica que la partición se formateó sin espacio para S_admin_group
+var ErrNoAdminGroupField = errors.New("la partición se formateó sin espacio para el grupo administrador, vuelva a formatearla con mkfs")
+
+// legacySuperblockSize es el tamaño del superbloque antes de S_admin_group
+// Las particiones formateadas antes solo reservan este tamaño y el bitmap de inodos empieza justo después
+var legacySuperblockSize = binary.Size(Superblock{}) - binary.Size([10]byte{})
+
+// HasAdminGroup indica si el superbloque que está en offset tiene espacio para S_admin_group
+// mkfs coloca el bitmap de inodos justo después del superbloque, así que su inicio indica cuánto espacio se reservó
+func (sb *Superblock) HasAdminGroup(offset int64) bool {
+	return int64(sb.S_bm_inode_start) >= offset+int64(binary.Size(sb))
+}
+
+// SetAdminGroup registra el grupo con privilegios de administración
+// offset es la posición del superbloque, falla si la partición no tiene espacio para guardarlo
+func (sb *Superblock) SetAdminGroup(group string, offset int64) error {
+	if !sb.HasAdminGroup(offset) {
+		return ErrNoAdminGroupField
+	}
+	sb.S_admin_group = [10]byte{}
+	copy(sb.S_admin_group[:], group)
+	return nil
+}
+
+// GetAdminGroup devuelve el nombre del grupo con privilegios de administración
+func (sb *Superblock) GetAdminGroup() string {
+	return strings.TrimRight(string(sb.S_admin_group[:]), "\x00")
 }
 
 // Encode codifica la estructura Superblock en un archivo
+// En las particiones sin espacio para S_admin_group solo se escriben los campos anteriores, para no pisar el bitmap de inodos
 func (sb *Superblock) Encode(file *os.File, offset int64) error {
-	return utilidades.WriteToFile(file, offset, sb)
+	if sb.HasAdminGroup(offset) {
+		return utilidades.WriteToFile(file, offset, sb)
+	}
+
+	var buffer bytes.Buffer
+	err := binary.Write(&buffer, binary.LittleEndian, sb)
+	if err != nil {
+		return fmt.Errorf("failed to write data to file: %w", err)
+	}
+	return utilidades.WriteBytes(file, offset, buffer.Bytes()[:legacySuperblockSize])
 }
 
 // Decode decodifica la estructura Superblock desde un archivo
+// En las particiones sin espacio para S_admin_group esos bytes son del bitmap de inodos y se descartan
 func (sb *Superblock) Decode(file *os.File, offset int64) error {
-	return utilidades.ReadFromFile(file, offset, sb)
+	err := utilidades.ReadFromFile(file, offset, sb)
+	if err != nil {
+		return err
+	}
+	if !sb.HasAdminGroup(offset) {
+		sb.S_admin_group = [10]byte{}
+	}
+	return nil
+}
+
+// Validate verifica que el superbloque pertenezca a un sistema de archivos ext2 o ext3
+// Una partición sin formatear se decodifica con ceros, por lo que el número mágico no coincide
+func (sb *Superblock) Validate() error {
+	if sb.S_magic != SuperblockMagic {
+		return ErrNotFormatted
+	}
+	if sb.S_filesystem_type != 2 && sb.S_filesystem_type != 3 {
+		return fmt.Errorf("sistema de archivos desconocido: ext%d", sb.S_filesystem_type)
+	}
+	if sb.S_inode_size <= 0 || sb.S_block_size <= 0 {
+		return fmt.Errorf("el superbloque está dañado: tamaño de inodo %d, tamaño de bloque %d", sb.S_inode_size, sb.S_block_size)
+	}
+	// Los bloques de carpeta y de apuntadores no caben en un bloque más pequeño
+	if sb.S_block_size < MinBlockSize {
+		return fmt.Errorf("el superbloque está dañado: el tamaño de bloque %d es menor al mínimo de %d bytes", sb.S_block_size, MinBlockSize)
+	}
+	return nil
 }
 
 func (sb *Superblock) CreateUsersFile(file *os.File) error {
+	// Reservar el inodo y el bloque de la raíz, al ser los primeros quedan en el índice 0
+	rootInodeIndex, err := sb.AllocateInode(file)
+	if err != nil {
+		return err
+	}
+	rootBlockIndex, err := sb.AllocateBlock(file)
+	if err != nil {
+		return err
+	}
+
+	// Reservar el inodo y el bloque de users.txt (índice 1)
+	usersInodeIndex, err := sb.AllocateInode(file)
+	if err != nil {
+		return err
+	}
+	usersBlockIndex, err := sb.AllocateBlock(file)
+	if err != nil {
+		return err
+	}
+
 	// ----------- Crear Inodo Raíz -----------
 	rootInode := &Inode{
 		I_uid:   1,
@@ -47,51 +143,33 @@ func (sb *Superblock) CreateUsersFile(file *os.File) error {
 		I_atime: float32(time.Now().Unix()),
 		I_ctime: float32(time.Now().Unix()),
 		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_block: [15]int32{rootBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
 		I_type:  [1]byte{'0'}, // Tipo carpeta
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Escribir el inodo raíz (inodo 0)
-	err := utilidades.WriteToFile(file, int64(sb.S_inode_start), rootInode)
+	err = utilidades.WriteToFile(file, sb.CalculateInodeOffset(rootInodeIndex), rootInode)
 	if err != nil {
 		return fmt.Errorf("error al escribir el inodo raíz: %w", err)
 	}
 
-	// Actualizar bitmap de inodos (índice 0)
-	err = sb.UpdateBitmapInode(file, 0, true)
-	if err != nil {
-		return fmt.Errorf("error al actualizar bitmap de inodos: %w", err)
-	}
-
-	// Actualizar el contador de inodos y el puntero al primer inodo libre
-	sb.UpdateSuperblockAfterInodeAllocation()
-
 	// ----------- Crear Bloque Raíz (/ carpeta) -----------
 	rootBlock := &FolderBlock{
 		B_content: [4]FolderContent{
-			{B_name: [12]byte{'.'}, B_inodo: 0},                                                         // Apunta a sí mismo
-			{B_name: [12]byte{'.', '.'}, B_inodo: 0},                                                    // Apunta al padre
-			{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}, // Apunta a users.txt
-			{B_name: [12]byte{'-'}, B_inodo: -1},                                                        // Vacío
+			{B_name: [12]byte{'.'}, B_inodo: rootInodeIndex},                                          // Apunta a sí mismo
+			{B_name: [12]byte{'.', '.'}, B_inodo: rootInodeIndex},                                     // Apunta al padre
+			{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: usersInodeIndex}, // Apunta a users.txt
+			{B_name: [12]byte{'-'}, B_inodo: -1},                                                      // Vacío
 		},
 	}
 
 	// Escribir el bloque raíz
-	err = utilidades.WriteToFile(file, int64(sb.S_block_start), rootBlock)
+	err = utilidades.WriteToFile(file, sb.CalculateBlockOffset(rootBlockIndex), rootBlock)
 	if err != nil {
 		return fmt.Errorf("error al escribir el bloque raíz: %w", err)
 	}
 
-	// Actualizar bitmap de bloques (índice 0)
-	err = sb.UpdateBitmapBlock(file, 0, true)
-	if err != nil {
-		return fmt.Errorf("error al actualizar el bitmap de bloques: %w", err)
-	}
-
-	// Actualizar el contador de bloques y el puntero al primer bloque libre
-	sb.UpdateSuperblockAfterBlockAllocation()
-
 	// ----------- Crear Inodo para /users.txt (inodo 1) -----------
 	rootGroup := NewGroup("1", "root")
 	rootUser := NewUser("1", "root", "root", "123")
@@ -104,45 +182,27 @@ func (sb *Superblock) CreateUsersFile(file *os.File) error {
 		I_atime: float32(time.Now().Unix()),
 		I_ctime: float32(time.Now().Unix()),
 		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, // Apunta al bloque 1 (users.txt)
-		I_type:  [1]byte{'1'},                                                         // Tipo archivo
+		I_block: [15]int32{usersBlockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, // Apunta al bloque de users.txt
+		I_type:  [1]byte{'1'},                                                                       // Tipo archivo
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
 	// Escribir el inodo de users.txt (inodo 1)
-	err = utilidades.WriteToFile(file, int64(sb.S_inode_start+int32(binary.Size(usersInode))), usersInode)
+	err = utilidades.WriteToFile(file, sb.CalculateInodeOffset(usersInodeIndex), usersInode)
 	if err != nil {
 		return fmt.Errorf("error al escribir el inodo de users.txt: %w", err)
 	}
 
-	// Actualizar bitmap de inodos (índice 1)
-	err = sb.UpdateBitmapInode(file, 1, true)
-	if err != nil {
-		return fmt.Errorf("error al actualizar bitmap de inodos para users.txt: %w", err)
-	}
-
-	// Actualizar el contador de inodos y el puntero al primer inodo libre
-	sb.UpdateSuperblockAfterInodeAllocation()
-
 	// ----------- Crear Bloque para users.txt (bloque 1) -----------
-	usersBlock := &FileBlock{}
-	copy(usersBlock.B_content[:], usersText)
+	usersBlock := sb.NewFileBlock()
+	copy(usersBlock.B_content, usersText)
 
 	// Escribir el bloque de users.txt
-	err = utilidades.WriteToFile(file, int64(sb.S_block_start+int32(binary.Size(usersBlock))), usersBlock)
+	err = usersBlock.Encode(file, sb.CalculateBlockOffset(usersBlockIndex))
 	if err != nil {
 		return fmt.Errorf("error al escribir el bloque de users.txt: %w", err)
 	}
 
-	// Actualizar el bitmap de bloques (índice 1)
-	err = sb.UpdateBitmapBlock(file, 1, true)
-	if err != nil {
-		return fmt.Errorf("error al actualizar el bitmap de bloques para users.txt: %w", err)
-	}
-
-	// Actualizar el contador de bloques y el puntero al primer bloque libre
-	sb.UpdateSuperblockAfterBlockAllocation()
-
 	fmt.Println("Archivo users.txt creado correctamente.")
 	fmt.Println("Superbloque después de la creación de users.txt:")
 	sb.Print()
@@ -174,6 +234,7 @@ func (sb *Superblock) Print() {
 	fmt.Printf("%-25s %-10d\n", "S_bm_block_start:", sb.S_bm_block_start)
 	fmt.Printf("%-25s %-10d\n", "S_inode_start:", sb.S_inode_start)
 	fmt.Printf("%-25s %-10d\n", "S_block_start:", sb.S_block_start)
+	fmt.Printf("%-25s %-10s\n", "S_admin_group:", sb.GetAdminGroup())
 }
 
 // PrintInodes imprime los inodos desde el archivo
@@ -185,19 +246,27 @@ func (sb *Superblock) PrintInodes(path string) error {
 	defer file.Close()
 
 	fmt.Println("\nInodos\n----------------")
-	inodes := make([]Inode, sb.S_inodes_count)
+	inodes := make(map[int32]Inode)
+	var indexes []int32
 
-	// Deserializar todos los inodos en memoria
-	for i := int32(0); i < sb.S_inodes_count; i++ {
-		inode := &inodes[i]
-		err := utilidades.ReadFromFile(file, int64(sb.S_inode_start+(i*int32(binary.Size(Inode{})))), inode)
+	// Deserializar los inodos en uso en memoria
+	inodeBitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return err
+	}
+	for i := inodeBitmap.findUsed(0); i < sb.S_inodes_count; i = inodeBitmap.findUsed(i + 1) {
+		inode := Inode{}
+		err = utilidades.ReadFromFile(file, int64(sb.S_inode_start+(i*int32(binary.Size(Inode{})))), &inode)
 		if err != nil {
 			return fmt.Errorf("failed to decode inode %d: %w", i, err)
 		}
+		inodes[i] = inode
+		indexes = append(indexes, i)
 	}
 
 	// Imprimir los inodos
-	for i, inode := range inodes {
+	for _, i := range indexes {
+		inode := inodes[i]
 		fmt.Printf("\nInodo %d:\n", i)
 		inode.Print()
 	}
@@ -214,19 +283,27 @@ func (sb *Superblock) PrintBlocks(path string) error {
 	defer file.Close()
 
 	fmt.Println("\nBloques\n----------------")
-	inodes := make([]Inode, sb.S_inodes_count)
+	inodes := make(map[int32]Inode)
+	var indexes []int32
 
-	// Deserializar todos los inodos en memoria
-	for i := int32(0); i < sb.S_inodes_count; i++ {
-		inode := &inodes[i]
-		err := utilidades.ReadFromFile(file, int64(sb.S_inode_start+(i*int32(binary.Size(Inode{})))), inode)
+	// Deserializar los inodos en uso en memoria
+	inodeBitmap, err := sb.inodeBitmap(file)
+	if err != nil {
+		return err
+	}
+	for i := inodeBitmap.findUsed(0); i < sb.S_inodes_count; i = inodeBitmap.findUsed(i + 1) {
+		inode := Inode{}
+		err = utilidades.ReadFromFile(file, int64(sb.S_inode_start+(i*int32(binary.Size(Inode{})))), &inode)
 		if err != nil {
 			return fmt.Errorf("failed to decode inode %d: %w", i, err)
 		}
+		inodes[i] = inode
+		indexes = append(indexes, i)
 	}
 
 	// Imprimir los bloques
-	for _, inode := range inodes {
+	for _, i := range indexes {
+		inode := inodes[i]
 		for _, blockIndex := range inode.I_block {
 			if blockIndex == -1 {
 				break
@@ -240,8 +317,8 @@ func (sb *Superblock) PrintBlocks(path string) error {
 				fmt.Printf("\nBloque %d:\n", blockIndex)
 				block.Print()
 			} else if inode.I_type[0] == '1' {
-				block := &FileBlock{}
-				err := utilidades.ReadFromFile(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size)), block)
+				block := sb.NewFileBlock()
+				err := block.Decode(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 				if err != nil {
 					return fmt.Errorf("failed to decode file block %d: %w", blockIndex, err)
 				}
@@ -254,150 +331,10 @@ func (sb *Superblock) PrintBlocks(path string) error {
 	return nil
 }
 
-// FindNextFreeBlock busca el siguiente bloque libre y lo marca como ocupado
-func (sb *Superblock) FindNextFreeBlock(file *os.File) (int32, error) {
-	totalBlocks := sb.S_blocks_count + sb.S_free_blocks_count // Número total de bloques
-
-	for position := int32(0); position < totalBlocks; position++ {
-		isFree, err := sb.isBlockFree(file, sb.S_bm_block_start, position)
-		if err != nil {
-			return -1, fmt.Errorf("error buscando bloque libre: %w", err)
-		}
-
-		if isFree {
-			// Marcar el bloque como ocupado
-			err = sb.UpdateBitmapBlock(file, position, true)
-			if err != nil {
-				return -1, fmt.Errorf("error actualizando el bitmap del bloque: %w", err)
-			}
-
-			// Devolver el índice del bloque libre encontrado
-			fmt.Println("Indice encontrado:", position)
-			return position, nil
-		}
-	}
-
-	// Si no hay bloques disponibles
-	return -1, fmt.Errorf("no hay bloques disponibles")
-}
-
-// FindNextFreeInode busca el siguiente inodo libre en el bitmap de inodos y lo marca como ocupado
-func (sb *Superblock) FindNextFreeInode(file *os.File) (int32, error) {
-	totalInodes := sb.S_inodes_count + sb.S_free_inodes_count // Número total de inodos
-
-	// Recorre todos los inodos en el bitmap
-	for position := int32(0); position < totalInodes; position++ {
-		// Verifica si el inodo está libre
-		isFree, err := sb.isInodeFree(file, sb.S_bm_inode_start, position)
-		if err != nil {
-			return -1, fmt.Errorf("error buscando inodo libre en la posición %d: %w", position, err)
-		}
-
-		// Si encontramos un inodo libre
-		if isFree {
-			// Marcar el inodo como ocupado
-			err = sb.UpdateBitmapInode(file, position, true)
-			if err != nil {
-				return -1, fmt.Errorf("error actualizando el bitmap del inodo en la posición %d: %w", position, err)
-			}
-			// Devolver la posición del inodo libre encontrado
-			fmt.Printf("Inodo libre encontrado y asignado: %d\n", position)
-			return position, nil
-		}
-	}
-
-	// Si no hay inodos disponibles
-	return -1, fmt.Errorf("no hay inodos disponibles")
-}
-
-// AssignNewBlock asigna un nuevo bloque al inodo en el índice especificado si es necesario
-func (sb *Superblock) AssignNewBlock(file *os.File, inode *Inode, index int) (int32, error) {
-	fmt.Println("=== Iniciando la asignación de un nuevo bloque ===")
-
-	// Validar que el índice esté dentro del rango de bloques válidos
-	if index < 0 || index >= len(inode.I_block) {
-		return -1, fmt.Errorf("índice de bloque fuera de rango: %d", index)
-	}
-
-	// Verificar si ya hay un bloque asignado en ese índice
-	if inode.I_block[index] != -1 {
-		return -1, fmt.Errorf("bloque en el índice %d ya está asignado: %d", index, inode.I_block[index])
-	}
-
-	// Intentar encontrar un bloque libre
-	newBlock, err := sb.FindNextFreeBlock(file)
-	if err != nil {
-		return -1, fmt.Errorf("error buscando nuevo bloque libre: %w", err)
-	}
-
-	// Verificar si se encontró un bloque libre
-	if newBlock == -1 {
-		return -1, fmt.Errorf("no hay bloques libres disponibles")
-	}
-
-	// Asignar el nuevo bloque en el índice especificado
-	inode.I_block[index] = newBlock
-	fmt.Printf("Nuevo bloque asignado: %d en I_block[%d]\n", newBlock, index)
-
-	// Actualizar el Superblock después de asignar el bloque
-	sb.UpdateSuperblockAfterBlockAllocation()
-
-	// Retornar el nuevo bloque asignado
-	return newBlock, nil
-}
-
-// AssignNewInode asigna un nuevo inodo pero no lo inicializa
-func (sb *Superblock) AssignNewInode(file *os.File, inode *Inode, index int) (int32, error) {
-	fmt.Println("=== Iniciando la asignación de un nuevo inodo ===")
-
-	// Validar que el índice esté dentro del rango de inodos válidos
-	if index < 0 || index >= len(inode.I_block) {
-		return -1, fmt.Errorf("índice de inodo fuera de rango: %d", index)
-	}
-
-	// Verificar si ya hay un inodo asignado en ese índice
-	if inode.I_block[index] != -1 {
-		return -1, fmt.Errorf("el inodo en el índice %d ya está asignado: %d", index, inode.I_block[index])
-	}
-
-	// Paso 1: Encontrar un inodo libre
-	newInodeIndex, err := sb.FindNextFreeInode(file)
-	if err != nil {
-		return -1, fmt.Errorf("error encontrando inodo libre: %w", err)
-	}
-
-	// Verificar si se encontró un inodo libre
-	if newInodeIndex == -1 {
-		return -1, fmt.Errorf("no hay inodos libres disponibles")
-	}
-
-	// Asignar el nuevo inodo en el índice especificado
-	inode.I_block[index] = newInodeIndex
-	fmt.Printf("Nuevo inodo asignado: %d en I_block[%d]\n", newInodeIndex, index)
-
-	// Paso 2: Actualizar el bitmap de inodos
-	err = sb.UpdateBitmapInode(file, newInodeIndex, true)
-	if err != nil {
-		return -1, fmt.Errorf("error actualizando el bitmap de inodos: %w", err)
-	}
-
-	// Paso 3: Actualizar el estado del superblock
-	sb.UpdateSuperblockAfterInodeAllocation()
-
-	// Retornar el índice del inodo asignado
-	return newInodeIndex, nil
-}
-
 // WriteInodeToFile escribe un inodo en la posición especificada del archivo
 func WriteInodeToFile(file *os.File, offset int64, inode *Inode) error {
-	// Mover el puntero al offset calculado
-	_, err := file.Seek(offset, 0)
-	if err != nil {
-		return fmt.Errorf("error buscando la posición para escribir el inodo: %w", err)
-	}
-
 	// Escribir el inodo en el archivo
-	err = binary.Write(file, binary.LittleEndian, inode)
+	err := utilidades.WriteToFile(file, offset, inode)
 	if err != nil {
 		return fmt.Errorf("error escribiendo el inodo en el archivo: %w", err)
 	}
@@ -410,26 +347,7 @@ func (sb *Superblock) CalculateInodeOffset(inodeIndex int32) int64 {
 	return int64(sb.S_inode_start) + int64(inodeIndex)*int64(sb.S_inode_size)
 }
 
-// UpdateSuperblockAfterBlockAllocation actualiza el Superblock después de asignar un bloque
-func (sb *Superblock) UpdateSuperblockAfterBlockAllocation() {
-	// Incrementa el contador de bloques asignados
-	sb.S_blocks_count++
-
-	// Decrementa el contador de bloques libres
-	sb.S_free_blocks_count--
-
-	// Actualiza el puntero al primer bloque libre
-	sb.S_first_blo += sb.S_block_size
-}
-
-// UpdateSuperblockAfterInodeAllocation actualiza el Superblock después de asignar un inodo
-func (sb *Superblock) UpdateSuperblockAfterInodeAllocation() {
-	// Incrementa el contador de inodos asignados
-	sb.S_inodes_count++
-
-	// Decrementa el contador de inodos libres
-	sb.S_free_inodes_count--
-
-	// Actualiza el puntero al primer inodo libre
-	sb.S_first_ino += sb.S_inode_size
+// CalculateBlockOffset calcula la posición de un bloque en el archivo
+func (sb *Superblock) CalculateBlockOffset(blockIndex int32) int64 {
+	return int64(sb.S_block_start) + int64(blockIndex)*int64(sb.S_block_size)
 }
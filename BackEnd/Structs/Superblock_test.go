@@ -0,0 +1,102 @@
+package structs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuperblockAdminGroupLayout(t *testing.T) {
+	const offset = 512
+	fullSize := binary.Size(Superblock{})
+
+	tests := []struct {
+		name          string
+		bmInodeStart  int64 // Inicio del bitmap de inodos que dejó mkfs
+		wantAdmin     bool  // El superbloque tiene espacio para S_admin_group
+		wantSetFailed bool
+	}{
+		{name: "formato actual", bmInodeStart: offset + int64(fullSize), wantAdmin: true},
+		{name: "formato anterior a S_admin_group", bmInodeStart: offset + int64(legacySuperblockSize), wantSetFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Create(filepath.Join(t.TempDir(), "disco.mia"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			// El bitmap de inodos empieza con todos los bits ocupados
+			bitmap := bytes.Repeat([]byte{0xFF}, 16)
+			_, err = file.WriteAt(bitmap, tt.bmInodeStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sb := &Superblock{S_magic: SuperblockMagic, S_bm_inode_start: int32(tt.bmInodeStart)}
+			if got := sb.HasAdminGroup(offset); got != tt.wantAdmin {
+				t.Fatalf("HasAdminGroup() = %v, se esperaba %v", got, tt.wantAdmin)
+			}
+			err = sb.SetAdminGroup("admins", offset)
+			if (err != nil) != tt.wantSetFailed {
+				t.Fatalf("SetAdminGroup() error = %v, se esperaba error: %v", err, tt.wantSetFailed)
+			}
+			if err := sb.Encode(file, offset); err != nil {
+				t.Fatal(err)
+			}
+
+			// Encode nunca debe escribir sobre el bitmap de inodos
+			got := make([]byte, len(bitmap))
+			_, err = file.ReadAt(got, tt.bmInodeStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, bitmap) {
+				t.Fatalf("el bitmap de inodos cambió: %x", got)
+			}
+
+			// Decode no debe interpretar el bitmap como el grupo administrador
+			decoded := &Superblock{}
+			if err := decoded.Decode(file, offset); err != nil {
+				t.Fatal(err)
+			}
+			wantGroup := ""
+			if tt.wantAdmin {
+				wantGroup = "admins"
+			}
+			if decoded.GetAdminGroup() != wantGroup {
+				t.Fatalf("GetAdminGroup() = %q, se esperaba %q", decoded.GetAdminGroup(), wantGroup)
+			}
+		})
+	}
+}
+
+func TestSuperblockValidateBlockSize(t *testing.T) {
+	// Los bloques de carpeta y de apuntadores tienen tamaño fijo y deben caber en el bloque más pequeño
+	if size := binary.Size(FolderBlock{}); size != MinBlockSize {
+		t.Fatalf("FolderBlock ocupa %d bytes, se esperaba %d", size, MinBlockSize)
+	}
+	if size := binary.Size(PointerBlock{}); size != MinBlockSize {
+		t.Fatalf("PointerBlock ocupa %d bytes, se esperaba %d", size, MinBlockSize)
+	}
+
+	tests := []struct {
+		blockSize int32
+		wantErr   bool
+	}{
+		{blockSize: 32, wantErr: true},
+		{blockSize: MinBlockSize},
+		{blockSize: 4096},
+	}
+	for _, tt := range tests {
+		sb := &Superblock{S_magic: SuperblockMagic, S_filesystem_type: 2, S_inode_size: 88, S_block_size: tt.blockSize}
+		err := sb.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate() con bloques de %d bytes: error = %v, se esperaba error: %v", tt.blockSize, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package structs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPartitionStart es el inicio de la partición dentro de las imágenes de prueba
+const testPartitionStart = 512
+
+// newTestDisk crea una imagen .mia temporal y la formatea como lo hace mkfs con n inodos y 3 bloques por inodo
+// La raíz y users.txt quedan creados, el archivo se cierra al terminar la prueba
+func newTestDisk(t *testing.T, n int32) (*os.File, *Superblock) {
+	t.Helper()
+	return newTestDiskFS(t, n, 2)
+}
+
+// newTestDiskFS es newTestDisk con el sistema de archivos indicado, 2 para ext2 o 3 para ext3
+func newTestDiskFS(t *testing.T, n int32, fs int32) (*os.File, *Superblock) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "disco.mia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	// La distribución de las estructuras es la misma que usa mkfs
+	sb := NewSuperblock(testPartitionStart, n, DefaultBlockSize, DefaultInodeRatio, fs)
+	err = file.Truncate(sb.End())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sb.Format(file, testPartitionStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, sb
+}
+
+// readInode lee el inodo indicado de la imagen de prueba
+func readInode(t *testing.T, file *os.File, sb *Superblock, index int32) *Inode {
+	t.Helper()
+	inode := &Inode{}
+	err := inode.Decode(file, sb.CalculateInodeOffset(index))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inode
+}
+
+// resolve devuelve el inodo del path y detiene la prueba si no existe
+func resolve(t *testing.T, file *os.File, sb *Superblock, path string) int32 {
+	t.Helper()
+	index, err := sb.ResolvePath(file, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+// writeEntry escribe una entrada en la posición slot del primer bloque de la carpeta
+func writeEntry(t *testing.T, file *os.File, sb *Superblock, dirIndex int32, slot int, name string, inode int32) {
+	t.Helper()
+	blockOffset := sb.CalculateBlockOffset(readInode(t, file, sb, dirIndex).I_block[0])
+	block := &FolderBlock{}
+	err := block.Decode(file, blockOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.B_content[slot] = FolderContent{B_inodo: inode}
+	copy(block.B_content[slot].B_name[:], name)
+	err = block.Encode(file, blockOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	global "backend/globals"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// FSCK estructura que representa el comando fsck con sus parámetros
+type FSCK struct {
+	id     string // ID de la partición montada
+	repair bool   // Opción -repair (corrige los problemas encontrados)
+}
+
+/*
+	fsck -id=061A
+	fsck -id=061A -repair
+*/
+
+func ParserFsck(tokens []string) (string, error) {
+	cmd := &FSCK{}                // Crea una nueva instancia de FSCK
+	var outputBuffer bytes.Buffer // Buffer para capturar mensajes importantes
+
+	args := strings.Join(tokens, " ")
+	// Expresión regular para encontrar los parámetros del comando fsck
+	re := regexp.MustCompile(`-id=[^\s]+|-repair`)
+	matches := re.FindAllString(args, -1)
+
+	// Verificar que todos los tokens fueron reconocidos por la expresión regular
+	if len(matches) != len(tokens) {
+		for _, token := range tokens {
+			if !re.MatchString(token) {
+				return "", fmt.Errorf("parámetro inválido: %s", token)
+			}
+		}
+	}
+
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		key := strings.ToLower(kv[0])
+
+		switch key {
+		case "-id":
+			value := strings.Trim(kv[1], "\"")
+			if value == "" {
+				return "", errors.New("el id no puede estar vacío")
+			}
+			cmd.id = value
+		case "-repair":
+			cmd.repair = true
+		default:
+			return "", fmt.Errorf("parámetro desconocido: %s", key)
+		}
+	}
+
+	if cmd.id == "" {
+		return "", errors.New("faltan parámetros requeridos: -id")
+	}
+
+	err := commandFsck(cmd, &outputBuffer)
+	if err != nil {
+		return "", err
+	}
+
+	return outputBuffer.String(), nil
+}
+
+func commandFsck(fsck *FSCK, outputBuffer *bytes.Buffer) error {
+	// Obtener el superbloque de la partición montada
+	sb, partition, partitionPath, err := global.GetMountedPartitionSuperblock(fsck.id)
+	if err != nil {
+		return fmt.Errorf("error al obtener la partición montada con ID %s: %v", fsck.id, err)
+	}
+
+	// Abrir el archivo de la partición, con -repair se escribe sobre él
+	flag := os.O_RDONLY
+	if fsck.repair {
+		flag = os.O_RDWR
+	}
+	file, err := os.OpenFile(partitionPath, flag, 0644)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de la partición: %w", err)
+	}
+	defer file.Close()
+
+	fmt.Fprintln(outputBuffer, "======================= FSCK =======================")
+	fmt.Fprintf(outputBuffer, "Verificando la partición %s\n", fsck.id)
+
+	result, err := sb.Check(file, fsck.repair)
+	if err != nil {
+		return fmt.Errorf("error al verificar el sistema de archivos: %w", err)
+	}
+
+	// Guardar los contadores corregidos antes de mostrar qué problemas quedaron reparados
+	if fsck.repair && len(result.Problems) > 0 {
+		err = sb.Encode(file, int64(partition.Part_start))
+		if err != nil {
+			return fmt.Errorf("error al serializar el superbloque: %w", err)
+		}
+		result.SuperblockWritten()
+	}
+
+	for _, problem := range result.Problems {
+		status := "encontrado"
+		if problem.Repaired {
+			status = "reparado"
+		}
+		fmt.Fprintf(outputBuffer, "[%s] %s\n", status, problem.Description)
+	}
+
+	fmt.Fprintf(outputBuffer, "Inodos alcanzables: %d de %d\n", result.UsedInodes, result.TotalInodes)
+	fmt.Fprintf(outputBuffer, "Bloques alcanzables: %d de %d\n", result.UsedBlocks, result.TotalBlocks)
+
+	switch {
+	case len(result.Problems) == 0:
+		fmt.Fprintln(outputBuffer, "El sistema de archivos no tiene problemas")
+	case fsck.repair:
+		fmt.Fprintf(outputBuffer, "Se repararon %d de %d problemas\n", result.RepairedCount(), len(result.Problems))
+	default:
+		fmt.Fprintf(outputBuffer, "Se encontraron %d problemas, use -repair para corregirlos\n", len(result.Problems))
+	}
+	fmt.Fprintln(outputBuffer, "====================================================")
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	structures "backend/Structs"
+	globals "backend/globals"
+	"backend/utils"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+	lsdisk
+*/
+
+// ParserLsdisk lista los discos registrados (sin parámetros)
+func ParserLsdisk(tokens []string) (string, error) {
+	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
+
+	// El comando lsdisk no debe recibir parámetros
+	if len(tokens) > 0 {
+		return "", errors.New("el comando lsdisk no acepta parámetros")
+	}
+
+	commandLsdisk(&outputBuffer)
+	return outputBuffer.String(), nil
+}
+
+// commandLsdisk muestra el tamaño, la firma y las particiones de cada disco registrado
+func commandLsdisk(outputBuffer *bytes.Buffer) {
+	fmt.Fprintln(outputBuffer, "======================== LSDISK ==========================")
+
+	paths := globals.DiskPaths()
+	if len(paths) == 0 {
+		fmt.Fprintln(outputBuffer, "No hay discos registrados, use mkdisk para crear uno")
+	}
+
+	for _, path := range paths {
+		fmt.Fprintf(outputBuffer, "Disco: %s\n", path)
+		err := printDisk(path, outputBuffer)
+		if err != nil {
+			// Un disco ilegible no impide listar los demás
+			fmt.Fprintf(outputBuffer, "  Error: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(outputBuffer, "===========================================================")
+}
+
+// printDisk escribe los datos del MBR y las particiones de un disco
+func printDisk(path string, outputBuffer *bytes.Buffer) error {
+	// Bloquear el disco para lectura mientras se lee el MBR y la cadena de EBR
+	unlock := globals.RLockDisk(path)
+	defer unlock()
+
+	// Como los demás comandos de lectura, recuperar primero una transacción que haya quedado a medias
+	tx, err := utils.BeginReadTransaction(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el disco: %v", err)
+	}
+	defer tx.Rollback()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo abrir el disco: %v", err)
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	err = mbr.Decode(file)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el MBR: %v", err)
+	}
+
+	fmt.Fprintf(outputBuffer, "  Tamaño: %d bytes | Firma: %d | Ajuste: %s\n", mbr.MbrSize, mbr.MbrDiskSignature, string(mbr.MbrDiskFit[:]))
+
+	empty := true
+	for _, partition := range mbr.MbrPartitions {
+		if partition.Part_size <= 0 || partition.Part_start <= 0 {
+			continue // Partición sin usar
+		}
+		empty = false
+
+		name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+		id := strings.TrimRight(string(partition.Part_id[:]), "\x00")
+		if globals.GetMountedPath(id) != path {
+			id = "-" // Solo se muestra el id de las particiones montadas
+		}
+		fmt.Fprintf(outputBuffer, "  [%s] %-16s inicio: %-9d tamaño: %-9d id: %s\n", string(partition.Part_type[:]), name, partition.Part_start, partition.Part_size, id)
+
+		if partition.Part_type[0] != 'E' {
+			continue
+		}
+
+		// Particiones lógicas dentro de la extendida
+		chain, err := structures.ReadEBRChain(partition.Part_start, file)
+		if err != nil {
+			return fmt.Errorf("no se pudo leer la cadena de EBR: %v", err)
+		}
+		ebrSize := int32(binary.Size(structures.EBR{}))
+		for _, ebr := range chain {
+			if ebr.Ebr_size <= ebrSize {
+				continue // EBR vacío
+			}
+			ebrName := strings.TrimRight(string(ebr.Ebr_name[:]), "\x00")
+			fmt.Fprintf(outputBuffer, "    [L] %-16s inicio: %-9d tamaño: %d\n", ebrName, ebr.Ebr_start+ebrSize, ebr.Ebr_size-ebrSize)
+		}
+	}
+
+	if empty {
+		fmt.Fprintln(outputBuffer, "  Sin particiones")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	structures "backend/Structs"
+	"backend/utils"
+	"os"
+	"strings"
+	"testing"
+)
+
+// interruptCommit ejecuta change dentro de una transacción del disco y simula una caída al confirmarla:
+// el registro queda guardado pero sus escrituras no llegan al disco
+func interruptCommit(t *testing.T, disk string, change func()) {
+	t.Helper()
+
+	tx, err := utils.BeginTransaction(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change()
+
+	// Una carpeta en el lugar del disco impide aplicar las escrituras después de guardar el registro
+	if err := os.Rename(disk, disk+".bak"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(disk, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit() no falló sin poder escribir en el disco")
+	}
+	if err := os.Remove(disk); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(disk+".bak", disk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(disk + ".wal"); err != nil {
+		t.Fatalf("no quedó el registro de la transacción: %v", err)
+	}
+}
+
+func TestReadCommandsRecoverWAL(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, disk string) // Cambio que queda solo en el registro
+		parser func(tokens []string) (string, error)
+		want   string
+	}{
+		{
+			name: "lsdisk",
+			change: func(t *testing.T, disk string) {
+				if _, err := ParserFdisk([]string{"-size=100", "-unit=K", "-path=" + disk, "-name=Part2"}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			parser: ParserLsdisk,
+			want:   "Part2",
+		},
+		{
+			name: "mounted",
+			change: func(t *testing.T, disk string) {
+				// Formatear Part1 con el mismo código que mkfs
+				file, err := os.OpenFile(disk, os.O_RDWR, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer file.Close()
+				var mbr structures.MBR
+				if err := mbr.Decode(file); err != nil {
+					t.Fatal(err)
+				}
+				partition, _ := mbr.GetPartitionByName("Part1")
+				n := structures.CalculateInodeCount(partition.Part_size, structures.DefaultBlockSize, structures.DefaultInodeRatio, 2)
+				sb := structures.NewSuperblock(partition.Part_start, n, structures.DefaultBlockSize, structures.DefaultInodeRatio, 2)
+				if err := sb.Format(file, int64(partition.Part_start)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			parser: ParserMounted,
+			want:   "Sistema de archivos: ext2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := newTestMountDisk(t)
+			if _, err := ParserMount([]string{"-path=" + disk, "-name=Part1"}); err != nil {
+				t.Fatal(err)
+			}
+			interruptCommit(t, disk, func() { tt.change(t, disk) })
+
+			// El comando de lectura completa la transacción antes de leer el disco
+			output, err := tt.parser(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Fatalf("la salida no contiene %q:\n%s", tt.want, output)
+			}
+			if _, err := os.Stat(disk + ".wal"); !os.IsNotExist(err) {
+				t.Fatalf("el registro de la transacción sigue en el disco: %v", err)
+			}
+		})
+	}
+}
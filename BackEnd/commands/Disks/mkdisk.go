@@ -2,6 +2,7 @@ package commands
 
 import (
 	structures "backend/Structs"
+	globals "backend/globals"
 	utils "backend/utils"
 	"bytes"
 	"errors"
@@ -117,24 +118,27 @@ func commandMkdisk(mkdisk *MkDisk, outputBuffer *bytes.Buffer) error {
 		return err
 	}
 
-	// Crear el disco con el tamaño proporcionado
+	// Crear el disco con el tamaño proporcionado y su MBR
 	err = createDisk(mkdisk, sizeBytes, outputBuffer)
 	if err != nil {
 		fmt.Fprintln(outputBuffer, "Error creando el disco:", err)
 		return err
 	}
 
-	// Crear el MBR con el tamaño proporcionado
-	err = createMBR(mkdisk, sizeBytes, outputBuffer)
+	// Registrar el disco para listarlo con lsdisk, cuando se confirma el comando
+	err = utils.AfterCommit(mkdisk.path, func() error {
+		return globals.RegisterDisk(mkdisk.path)
+	})
 	if err != nil {
-		fmt.Fprintln(outputBuffer, "Error creando el MBR:", err)
-		return err
+		fmt.Fprintf(outputBuffer, "Advertencia: %v, el disco no quedará registrado al reiniciar el servidor\n", err)
 	}
 
 	fmt.Fprintln(outputBuffer, "===========================================================")
 	return nil
 }
 
+// createDisk crea el disco con un nombre temporal y lo renombra al terminar
+// Así un disco a medio crear nunca queda con el nombre final, ni reemplaza a uno existente si algo falla
 func createDisk(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error {
 	// Crear las carpetas necesarias
 	err := os.MkdirAll(filepath.Dir(mkdisk.path), os.ModePerm)
@@ -143,39 +147,62 @@ func createDisk(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error
 		return err
 	}
 
-	// Crear el archivo binario
-	file, err := os.Create(mkdisk.path)
+	// Crear el archivo binario temporal en la misma carpeta para que el renombre sea atómico
+	file, err := os.CreateTemp(filepath.Dir(mkdisk.path), filepath.Base(mkdisk.path)+".*.tmp")
 	if err != nil {
 		fmt.Fprintln(outputBuffer, "Error creando archivo:", err)
 		return err
 	}
-	defer file.Close()
+	tmpPath := file.Name()
+	defer func() {
+		file.Close()
+		os.Remove(tmpPath) // No hace nada si el disco ya se renombró
+	}()
+
+	// CreateTemp crea el archivo solo con permisos para el dueño
+	err = file.Chmod(0644)
+	if err != nil {
+		return err
+	}
 
 	// Escribir en el archivo usando un buffer de 1 MB
 	buffer := make([]byte, 1024*1024) // Crea un buffer de 1 MB
-	for sizeBytes > 0 {
+	for remaining := sizeBytes; remaining > 0; {
 		writeSize := len(buffer)
-		if sizeBytes < writeSize {
-			writeSize = sizeBytes // Ajusta el tamaño de escritura si es menor que el buffer
+		if remaining < writeSize {
+			writeSize = remaining // Ajusta el tamaño de escritura si es menor que el buffer
 		}
 		if _, err := file.Write(buffer[:writeSize]); err != nil {
 			return err // Devuelve un error si la escritura falla
 		}
-		sizeBytes -= writeSize // Resta el tamaño escrito del tamaño total
+		remaining -= writeSize // Resta el tamaño escrito del tamaño total
+	}
+
+	// El archivo temporal no tiene transacción, el MBR se escribe directo en él
+	err = createMBR(file, mkdisk, sizeBytes, outputBuffer)
+	if err != nil {
+		fmt.Fprintln(outputBuffer, "Error creando el MBR:", err)
+		return err
 	}
-	fmt.Fprintln(outputBuffer, "Disco creado exitosamente:", mkdisk.path)
-	return nil
-}
 
-func createMBR(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error {
-	// Abrir el archivo del disco para escritura
-	file, err := os.OpenFile(mkdisk.path, os.O_WRONLY|os.O_CREATE, 0644)
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+	err = file.Close()
 	if err != nil {
-		fmt.Fprintln(outputBuffer, "Error abriendo el archivo:", err)
 		return err
 	}
-	defer file.Close()
+	err = os.Rename(tmpPath, mkdisk.path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(outputBuffer, "Disco creado exitosamente:", mkdisk.path)
+	return nil
+}
 
+// createMBR escribe el MBR al inicio del disco abierto en file
+func createMBR(file *os.File, mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error {
 	// Crear el MBR con los valores proporcionados
 	mbr := &structures.MBR{
 		MbrSize:          int32(sizeBytes),
@@ -191,7 +218,7 @@ func createMBR(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error
 	}
 
 	// Serializar el MBR en el archivo usando el puntero de archivo `file`
-	err = mbr.Encode(file)
+	err := mbr.Encode(file)
 	if err != nil {
 		fmt.Fprintln(outputBuffer, "Error serializando el MBR en el archivo:", err)
 		return err
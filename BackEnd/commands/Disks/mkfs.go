@@ -3,29 +3,39 @@ package commands
 import (
 	structures "backend/Structs"
 	global "backend/globals"
+	"backend/utils"
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
-	"math"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // MKFS estructura que representa el comando mkfs con sus parámetros
 type MKFS struct {
-	id  string // ID del disco
-	typ string // Tipo de formato (full)
+	id         string // ID del disco
+	typ        string // Tipo de formato (fast o full)
+	bs         int32  // Tamaño de bloque en bytes (-bs)
+	inodeRatio int32  // Cantidad de bloques por inodo (-inoderatio)
+	fs         int32  // Sistema de archivos, 2 para ext2 o 3 para ext3 (-fs)
 }
 
+/*
+	mkfs -id=061A
+	mkfs -id=061A -type=fast
+	mkfs -id=061A -bs=128 -inoderatio=2
+	mkfs -id=061A -fs=3fs
+*/
+
 func ParserMkfs(tokens []string) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
 	cmd := &MKFS{}
 
 	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[^\s]+|-type=[^\s]+`)
+	re := regexp.MustCompile(`-id=[^\s]+|-type=[^\s]+|-bs=[^\s]+|-inoderatio=[^\s]+|-fs=[^\s]+`)
 	matches := re.FindAllString(args, -1)
 
 	for _, match := range matches {
@@ -45,10 +55,35 @@ func ParserMkfs(tokens []string) (string, error) {
 			}
 			cmd.id = value
 		case "-type":
-			if value != "full" {
-				return "", errors.New("el tipo debe ser full")
+			value = strings.ToLower(value)
+			if value != "fast" && value != "full" {
+				return "", errors.New("el tipo debe ser fast o full")
 			}
 			cmd.typ = value
+		case "-bs":
+			// El tamaño de bloque debe ser una potencia de 2 entre 64 y 4096 bytes
+			// Con menos de 64 bytes no caben los bloques de carpeta ni los de apuntadores
+			bs, err := strconv.Atoi(value)
+			if err != nil || bs < structures.MinBlockSize || bs > 4096 || bs&(bs-1) != 0 {
+				return "", errors.New("el tamaño de bloque debe ser una potencia de 2 entre 64 y 4096")
+			}
+			cmd.bs = int32(bs)
+		case "-inoderatio":
+			// Cantidad de bloques que se reservan por cada inodo
+			ratio, err := strconv.Atoi(value)
+			if err != nil || ratio < 1 {
+				return "", errors.New("la proporción de bloques por inodo debe ser un número entero mayor a 0")
+			}
+			cmd.inodeRatio = int32(ratio)
+		case "-fs":
+			switch strings.ToLower(value) {
+			case "2fs":
+				cmd.fs = 2
+			case "3fs":
+				cmd.fs = 3
+			default:
+				return "", errors.New("el sistema de archivos debe ser 2fs o 3fs")
+			}
 		default:
 			return "", fmt.Errorf("parámetro desconocido: %s", key)
 		}
@@ -62,6 +97,18 @@ func ParserMkfs(tokens []string) (string, error) {
 		cmd.typ = "full"
 	}
 
+	if cmd.bs == 0 {
+		cmd.bs = structures.DefaultBlockSize
+	}
+
+	if cmd.inodeRatio == 0 {
+		cmd.inodeRatio = structures.DefaultInodeRatio
+	}
+
+	if cmd.fs == 0 {
+		cmd.fs = 2
+	}
+
 	err := commandMkfs(cmd, &outputBuffer)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -91,83 +138,49 @@ func commandMkfs(mkfs *MKFS, outputBuffer *bytes.Buffer) error {
 	fmt.Println("\nPartición montada:") // Mensaje de depuración
 	mountedPartition.Print()
 
+	start := time.Now()
+
+	// El formateo full llena de ceros toda la partición, el fast solo reescribe las estructuras
+	if mkfs.typ == "full" {
+		err = utils.ZeroFill(file, int64(mountedPartition.Part_start), int64(mountedPartition.Part_size))
+		if err != nil {
+			return fmt.Errorf("error al llenar de ceros la partición: %v", err)
+		}
+		fmt.Fprintf(outputBuffer, "Partición llenada con ceros (%d bytes).\n", mountedPartition.Part_size)
+	}
+
 	// Calcular el valor de n
-	n := calculateN(mountedPartition)
+	n := structures.CalculateInodeCount(mountedPartition.Part_size, mkfs.bs, mkfs.inodeRatio, mkfs.fs)
 	fmt.Println("\nValor de n:", n) // Depuración
+	if n <= 0 {
+		return fmt.Errorf("la partición es muy pequeña para un tamaño de bloque de %d bytes y %d bloques por inodo", mkfs.bs, mkfs.inodeRatio)
+	}
 
 	// Crear el superblock
-	superBlock := createSuperBlock(mountedPartition, n)
+	superBlock := structures.NewSuperblock(mountedPartition.Part_start, n, mkfs.bs, mkfs.inodeRatio, mkfs.fs)
 	fmt.Println("\nSuperBlock:") // Depuración
 	superBlock.Print()
 
-	// Crear bitmaps
-	err = superBlock.CreateBitMaps(file)
+	// Crear el journal (ext3), los bitmaps, el archivo users.txt y serializar el superbloque
+	err = superBlock.Format(file, int64(mountedPartition.Part_start))
 	if err != nil {
-		return fmt.Errorf("error creando bitmaps: %v", err)
+		return err
 	}
-	fmt.Fprintln(outputBuffer, "Bitmaps creados correctamente.")
-
-	// Crear el archivo users.txt
-	err = superBlock.CreateUsersFile(file)
-	if err != nil {
-		return fmt.Errorf("error creando el archivo users.txt: %v", err)
+	if mkfs.fs == 3 {
+		fmt.Fprintf(outputBuffer, "Journal creado con %d entradas.\n", superBlock.S_inodes_count)
 	}
+	fmt.Fprintf(outputBuffer, "Tamaño de bloque: %d bytes, %d bloques por inodo (%d inodos, %d bloques).\n", superBlock.S_block_size, mkfs.inodeRatio, superBlock.S_inodes_count, superBlock.S_blocks_count)
+	fmt.Fprintln(outputBuffer, "Bitmaps creados correctamente.")
 	fmt.Fprintln(outputBuffer, "Archivo users.txt creado correctamente.")
+	fmt.Fprintln(outputBuffer, "Superbloque escrito correctamente en el disco.")
 
-	// Serializar el superbloque
-	err = superBlock.Encode(file, int64(mountedPartition.Part_start))
+	// Aplicar las escrituras pendientes para que el tiempo incluya la escritura real en el disco
+	err = utils.Flush(file)
 	if err != nil {
-		return fmt.Errorf("error al escribir el superbloque en la partición: %v", err)
+		return fmt.Errorf("error al escribir el formateo en el disco: %v", err)
 	}
-	fmt.Fprintln(outputBuffer, "Superbloque escrito correctamente en el disco.")
+	fmt.Fprintf(outputBuffer, "Formateo %s completado en %v.\n", mkfs.typ, time.Since(start))
 	fmt.Fprintln(outputBuffer, "===========================================================")
 
 	return nil
 }
-
-func calculateN(partition *structures.Partition) int32 {
-	/*
-		numerador = (partition_montada.size - sizeof(Structs::Superblock)
-		denrominador base = (4 + sizeof(Structs::Inodes) + 3 * sizeof(Structs::Fileblock))
-		n = floor(numerador / denrominador)
-	*/
-
-	numerator := int(partition.Part_size) - binary.Size(structures.Superblock{})
-	denominator := 4 + binary.Size(structures.Inode{}) + 3*binary.Size(structures.FileBlock{}) // No importa que bloque poner, ya que todos tienen el mismo tamaño
-	n := math.Floor(float64(numerator) / float64(denominator))
-
-	return int32(n)
-}
-
-func createSuperBlock(partition *structures.Partition, n int32) *structures.Superblock {
-	// Calcular punteros de las estructuras
-	// Bitmaps
-	bm_inode_start := partition.Part_start + int32(binary.Size(structures.Superblock{}))
-	bm_block_start := bm_inode_start + n // n indica la cantidad de inodos, solo la cantidad para ser representada en un bitmap
-	// Inodos
-	inode_start := bm_block_start + (3 * n) // 3*n indica la cantidad de bloques, se multiplica por 3 porque se tienen 3 tipos de bloques
-	// Bloques
-	block_start := inode_start + (int32(binary.Size(structures.Inode{})) * n) // n indica la cantidad de inodos, solo que aquí indica la cantidad de estructuras Inode
-
-	// Crear un nuevo superbloque
-	superBlock := &structures.Superblock{
-		S_filesystem_type:   2,
-		S_inodes_count:      0,
-		S_blocks_count:      0,
-		S_free_inodes_count: int32(n),
-		S_free_blocks_count: int32(n * 3),
-		S_mtime:             float64(time.Now().Unix()),
-		S_umtime:            float64(time.Now().Unix()),
-		S_mnt_count:         1,
-		S_magic:             0xEF53,
-		S_inode_size:        int32(binary.Size(structures.Inode{})),
-		S_block_size:        int32(binary.Size(structures.FileBlock{})),
-		S_first_ino:         inode_start,
-		S_first_blo:         block_start,
-		S_bm_inode_start:    bm_inode_start,
-		S_bm_block_start:    bm_block_start,
-		S_inode_start:       inode_start,
-		S_block_start:       block_start,
-	}
-	return superBlock
-}
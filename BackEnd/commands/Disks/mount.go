@@ -10,6 +10,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Mount struct {
@@ -95,10 +96,8 @@ func commandMount(mount *Mount, outputBuffer *bytes.Buffer) error {
 	}
 
 	// Verificar si la partición ya está montada
-	for id, mountedPath := range globals.MountedPartitions {
-		if mountedPath == mount.path && strings.Contains(id, mount.name) {
-			return fmt.Errorf("error: la partición '%s' ya está montada con ID: %s", mount.name, id)
-		}
+	if id := mountedID(&mbr, mount.path, indexPartition); id != "" {
+		return fmt.Errorf("error: la partición '%s' ya está montada con ID: %s", mount.name, id)
 	}
 
 	// Generar ID único para la partición
@@ -107,9 +106,6 @@ func commandMount(mount *Mount, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error generando el ID de la partición: %v", err)
 	}
 
-	// Guardar la partición montada en la lista de particiones montadas globales
-	globals.MountedPartitions[idPartition] = mount.path
-
 	// Actualizar la partición como montada en el MBR
 	partition.MountPartition(indexPartition, idPartition)
 	mbr.MbrPartitions[indexPartition] = *partition
@@ -122,8 +118,37 @@ func commandMount(mount *Mount, outputBuffer *bytes.Buffer) error {
 
 	// Imprimir el estado de las particiones montadas
 	fmt.Fprintf(outputBuffer, "Partición '%s' montada correctamente con ID: %s\n", mount.name, idPartition)
+
+	// Guardar la partición montada en la tabla global, se conserva si el servidor se reinicia
+	// Solo se agrega cuando se confirman las escrituras del comando, si el comando falla no queda montada
+	err = utils.AfterCommit(mount.path, func() error {
+		return globals.AddMountedPartition(idPartition, mount.path)
+	})
+	if err != nil {
+		fmt.Fprintf(outputBuffer, "Advertencia: %v, el montaje se perderá al reiniciar el servidor\n", err)
+	}
+
+	// Detectar el sistema de archivos y registrar el montaje en el superbloque
+	var sb structures.Superblock
+	err = sb.Decode(file, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error leyendo el superbloque de la partición: %v", err)
+	}
+	if sb.Validate() == nil {
+		sb.S_mnt_count++
+		sb.S_mtime = float64(time.Now().Unix())
+		err = sb.Encode(file, int64(partition.Part_start))
+		if err != nil {
+			return fmt.Errorf("error actualizando el superbloque de la partición: %v", err)
+		}
+		fmt.Fprintf(outputBuffer, "Sistema de archivos ext%d detectado, montajes: %d\n", sb.S_filesystem_type, sb.S_mnt_count)
+	} else {
+		fmt.Fprintln(outputBuffer, "La partición no está formateada, use mkfs para formatearla")
+	}
 	fmt.Fprintln(outputBuffer, "\n=== Particiones Montadas ===")
-	for id, path := range globals.MountedPartitions {
+	mounted := globals.MountedPartitions()
+	mounted[idPartition] = utils.DiskKey(mount.path)
+	for id, path := range mounted {
 		fmt.Fprintf(outputBuffer, "ID: %s | Path: %s\n", id, path)
 	}
 	fmt.Fprintln(outputBuffer, "===========================================================")
@@ -131,6 +156,21 @@ func commandMount(mount *Mount, outputBuffer *bytes.Buffer) error {
 	return nil
 }
 
+// mountedID devuelve el ID con el que está montada la partición indexPartition del disco, vacío si no está montada
+// Se compara la partición exacta a la que apunta cada ID montado del disco, no su nombre
+func mountedID(mbr *structures.MBR, path string, indexPartition int) string {
+	for id, mountedPath := range globals.MountedPartitions() {
+		if mountedPath != utils.DiskKey(path) {
+			continue
+		}
+		partition, err := mbr.GetPartitionByID(id)
+		if err == nil && partition == &mbr.MbrPartitions[indexPartition] {
+			return id
+		}
+	}
+	return ""
+}
+
 // GenerateIdPartition genera un ID único para la partición montada
 func GenerateIdPartition(mount *Mount, indexPartition int) (string, error) {
 	lastTwoDigits := globals.Carnet[len(globals.Carnet)-2:]
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	structures "backend/Structs"
+	globals "backend/globals"
+	"backend/utils"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestMountDisk crea un disco con la partición Part1 y deja el estado de montajes en un directorio temporal
+func newTestMountDisk(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	stateFile := globals.MountStateFile
+	globals.MountStateFile = filepath.Join(dir, "mount_state.json")
+	disk := filepath.Join(dir, "disco.mia")
+	t.Cleanup(func() {
+		globals.UnregisterDisk(disk)
+		globals.MountStateFile = stateFile
+	})
+
+	if _, err := ParserMkdisk([]string{"-size=1", "-unit=M", "-path=" + disk}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParserFdisk([]string{"-size=100", "-unit=K", "-path=" + disk, "-name=Part1"}); err != nil {
+		t.Fatal(err)
+	}
+	return disk
+}
+
+func TestMountAppliedOnlyAfterCommit(t *testing.T) {
+	tests := []struct {
+		name        string
+		finish      func(t *testing.T, disk string, tx *utils.Transaction) // Termina la transacción del comando mount
+		wantMounted bool
+	}{
+		{
+			name: "se confirma",
+			finish: func(t *testing.T, disk string, tx *utils.Transaction) {
+				if err := tx.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantMounted: true,
+		},
+		{
+			name: "el comando falla después de montar",
+			finish: func(t *testing.T, disk string, tx *utils.Transaction) {
+				tx.Rollback()
+			},
+		},
+		{
+			name: "falla la confirmación",
+			finish: func(t *testing.T, disk string, tx *utils.Transaction) {
+				// Una carpeta en el lugar del registro impide escribirlo, el disco no se modifica
+				if err := os.Mkdir(disk+".wal", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := tx.Commit(); err == nil {
+					t.Fatal("Commit() no falló sin poder escribir el registro")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := newTestMountDisk(t)
+
+			tx, err := utils.BeginTransaction(disk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var output bytes.Buffer
+			err = commandMount(&Mount{path: disk, name: "Part1"}, &output)
+			if err != nil {
+				tx.Rollback()
+				t.Fatal(err)
+			}
+			// Hasta confirmar la transacción la partición no está en la tabla de montajes
+			if ids := globals.MountedIDsOnDisk(disk); len(ids) != 0 {
+				tx.Rollback()
+				t.Fatalf("la partición se montó antes de confirmar: %q", ids)
+			}
+			tt.finish(t, disk, tx)
+
+			// El ID que mount informó solo queda en la tabla, el MBR y el archivo de estado si se confirmó
+			id := strings.Fields(strings.SplitN(output.String(), "con ID: ", 2)[1])[0]
+			if ids := globals.MountedIDsOnDisk(disk); slices.Contains(ids, id) != tt.wantMounted || len(ids) > 1 {
+				t.Fatalf("particiones montadas = %q, se esperaba %s montada: %v", ids, id, tt.wantMounted)
+			}
+			if got := readPartID(t, disk); (got == id) != tt.wantMounted {
+				t.Fatalf("el MBR tiene el id %q, se esperaba %s montada: %v", got, id, tt.wantMounted)
+			}
+			state, err := os.ReadFile(globals.MountStateFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(state), `"`+id+`"`) != tt.wantMounted {
+				t.Fatalf("estado guardado inesperado para %s montada: %v\n%s", id, tt.wantMounted, state)
+			}
+		})
+	}
+}
+
+// readPartID lee el id que el MBR del disco tiene guardado para Part1
+func readPartID(t *testing.T, disk string) string {
+	t.Helper()
+	file, err := os.Open(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var mbr structures.MBR
+	if err := mbr.Decode(file); err != nil {
+		t.Fatal(err)
+	}
+	partition, _ := mbr.GetPartitionByName("Part1")
+	return strings.TrimRight(string(partition.Part_id[:]), "\x00")
+}
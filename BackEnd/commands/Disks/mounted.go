@@ -0,0 +1,94 @@
+package commands
+
+import (
+	structures "backend/Structs"
+	globals "backend/globals"
+	"backend/utils"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+	mounted
+*/
+
+// ParserMounted lista las particiones montadas (sin parámetros)
+func ParserMounted(tokens []string) (string, error) {
+	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
+
+	// El comando mounted no debe recibir parámetros
+	if len(tokens) > 0 {
+		return "", errors.New("el comando mounted no acepta parámetros")
+	}
+
+	commandMounted(&outputBuffer)
+	return outputBuffer.String(), nil
+}
+
+// commandMounted muestra el id, el disco, el nombre y el sistema de archivos de cada partición montada
+func commandMounted(outputBuffer *bytes.Buffer) {
+	fmt.Fprintln(outputBuffer, "======================== MOUNTED =========================")
+
+	ids := globals.MountedIDs()
+	if len(ids) == 0 {
+		fmt.Fprintln(outputBuffer, "No hay particiones montadas, use mount para montar una")
+	}
+
+	for _, id := range ids {
+		path := globals.GetMountedPath(id)
+		name, fsType, err := describeMountedPartition(id, path)
+		if err != nil {
+			// Una partición ilegible no impide listar las demás
+			fmt.Fprintf(outputBuffer, "ID: %s | Path: %s | Error: %v\n", id, path, err)
+			continue
+		}
+		fmt.Fprintf(outputBuffer, "ID: %s | Path: %s | Partición: %s | Sistema de archivos: %s\n", id, path, name, fsType)
+	}
+
+	fmt.Fprintln(outputBuffer, "===========================================================")
+}
+
+// describeMountedPartition devuelve el nombre de la partición y su sistema de archivos, "sin formato" si no está formateada
+func describeMountedPartition(id string, path string) (string, string, error) {
+	// Bloquear el disco para lectura mientras se leen el MBR y el superbloque
+	unlock := globals.RLockDisk(path)
+	defer unlock()
+
+	// Como los demás comandos de lectura, recuperar primero una transacción que haya quedado a medias
+	tx, err := utils.BeginReadTransaction(path)
+	if err != nil {
+		return "", "", fmt.Errorf("no se pudo leer el disco: %v", err)
+	}
+	defer tx.Rollback()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("no se pudo abrir el disco: %v", err)
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	err = mbr.Decode(file)
+	if err != nil {
+		return "", "", fmt.Errorf("no se pudo leer el MBR: %v", err)
+	}
+
+	partition, err := mbr.GetPartitionByID(id)
+	if err != nil {
+		return "", "", err
+	}
+	name := strings.TrimRight(string(partition.Part_name[:]), "\x00")
+
+	var sb structures.Superblock
+	err = sb.Decode(file, int64(partition.Part_start))
+	if err != nil {
+		return "", "", fmt.Errorf("no se pudo leer el superbloque: %v", err)
+	}
+	if sb.Validate() != nil {
+		return name, "sin formato", nil
+	}
+	return name, fmt.Sprintf("ext%d", sb.S_filesystem_type), nil
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	globals "backend/globals"
+	"backend/utils"
 	"bytes"
 	"errors"
 	"fmt"
@@ -86,6 +88,19 @@ func commandRmdisk(rmdisk *rmDisk, outputBuffer *bytes.Buffer) error {
 	}
 
 	fmt.Fprintf(outputBuffer, "Disco en %s eliminado exitosamente.\n", rmdisk.path)
+
+	// Quitar el disco del registro junto con sus particiones montadas cuando se confirma el comando
+	unmounted := globals.MountedIDsOnDisk(rmdisk.path)
+	if len(unmounted) > 0 {
+		fmt.Fprintf(outputBuffer, "Particiones desmontadas: %s\n", strings.Join(unmounted, ", "))
+	}
+	err = utils.AfterCommit(rmdisk.path, func() error {
+		_, err := globals.UnregisterDisk(rmdisk.path)
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(outputBuffer, "Advertencia: %v\n", err)
+	}
 	fmt.Fprintln(outputBuffer, "========================================================================")
 	return nil
 }
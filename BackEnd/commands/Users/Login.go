@@ -62,21 +62,20 @@ func ParserLogin(tokens []string) (string, error) {
 	return outputBuffer.String(), nil
 }
 
-// Lógica para ejecutar el login
 // Lógica para ejecutar el login
 func commandLogin(login *LOGIN, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintln(outputBuffer, "===== INICIO DE LOGIN =====") // Mensaje importante para el usuario
 	fmt.Fprintf(outputBuffer, "Intentando iniciar sesión con ID: %s, Usuario: %s\n", login.ID, login.User)
 
 	// 1. Validar si ya hay una sesión activa
-	if globals.UsuarioActual != nil && globals.UsuarioActual.Status { //verifica en el archivo globals si hay un usuario logueado
+	if globals.CurrentUser() != nil { //verifica en el archivo globals si hay un usuario logueado
 		return fmt.Errorf("ya hay un usuario logueado, debe cerrar sesión primero")
 	}
 
 	// Ver las particiones montadas
 	fmt.Println("Particiones montadas:")
-	for id, path := range globals.MountedPartitions {
-		fmt.Println("ID: %s | Path: %s\n", id, path)
+	for id, path := range globals.MountedPartitions() {
+		fmt.Printf("ID: %s | Path: %s\n", id, path)
 	}
 
 	// 2. Verificar que la partición esté montada
@@ -122,10 +121,10 @@ func commandLogin(login *LOGIN, outputBuffer *bytes.Buffer) error {
 			continue
 		}
 
-		blockOffset := int64(sb.S_block_start + blockIndex*int32(binary.Size(structs.FileBlock{})))
+		blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
 		fmt.Printf("Leyendo bloque en la posición: %d (índice de bloque: %d)\n", blockOffset, blockIndex) // Mensaje de depuración
 
-		var fileBlock structs.FileBlock
+		fileBlock := sb.NewFileBlock()
 		err = fileBlock.Decode(file, blockOffset)
 		if err != nil {
 			return fmt.Errorf("error leyendo bloque de users.txt: %v", err)
@@ -154,11 +153,13 @@ func commandLogin(login *LOGIN, outputBuffer *bytes.Buffer) error {
 			// Comparar usuario y contraseña
 			if usuario.Name == login.User && usuario.Password == login.Pass {
 				encontrado = true
-				globals.UsuarioActual = usuario
-				globals.UsuarioActual.Status = true
-				fmt.Fprintf(outputBuffer, "Bienvenido %s, inicio de sesión exitoso.\n", usuario.Name) // Mensaje importante para el usuario
 				// Guardar el ID de la partición montada
-				globals.UsuarioActual.Id = login.ID
+				usuario.Id = login.ID
+				err = globals.StartSession(usuario)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(outputBuffer, "Bienvenido %s, inicio de sesión exitoso.\n", usuario.Name) // Mensaje importante para el usuario
 				break
 			}
 		}
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	structs "backend/Structs"
 	globals "backend/globals"
 	"bytes"
 	"fmt"
@@ -32,19 +31,15 @@ func ParserLogout(tokens []string) (string, error) {
 
 // commandLogout ejecuta el comando LOGOUT, y captura los mensajes importantes en un buffer
 func commandLogout(outputBuffer *bytes.Buffer) error {
-	// Verificar si hay una sesión activa
-	if globals.UsuarioActual == nil || !globals.UsuarioActual.Status {
+	// Cerrar la sesión, si no había una activa no hay nada que cerrar
+	usuario := globals.Logout()
+	if usuario == nil {
 		return fmt.Errorf("no hay ninguna sesión activa")
 	}
 
 	// Mensaje importante para el usuario
-	fmt.Fprintf(outputBuffer, "Cerrando sesión de usuario: %s\n", globals.UsuarioActual.Name)
-
-	// Cerrar la sesión
-	fmt.Printf("Cerrando sesión de usuario: %s\n", globals.UsuarioActual.Name) // Mensaje de depuración
-
-	// Reiniciar la estructura del usuario actual
-	globals.UsuarioActual = &structs.User{}
+	fmt.Fprintf(outputBuffer, "Cerrando sesión de usuario: %s\n", usuario.Name)
+	fmt.Printf("Cerrando sesión de usuario: %s\n", usuario.Name) // Mensaje de depuración
 
 	// Mensaje de éxito importante para el usuario
 	fmt.Fprintln(outputBuffer, "Sesión cerrada correctamente.")
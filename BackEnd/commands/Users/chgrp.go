@@ -17,7 +17,7 @@ type CHGRP struct {
 }
 
 // ParserChgrp : Parseo de argumentos para el comando chgrp
-func ParserChgrp(tokens []string) (string, error) {
+func ParserChgrp(tokens []string, user *structs.User) (string, error) {
 	// Inicializar el comando CHGRP
 	var outputBuffer strings.Builder
 	cmd := &CHGRP{}
@@ -42,7 +42,7 @@ func ParserChgrp(tokens []string) (string, error) {
 	cmd.Grp = strings.SplitN(matchesGrp, "=", 2)[1]
 
 	// Ejecutar la lógica del comando chgrp
-	err := commandChgrp(cmd, &outputBuffer)
+	err := commandChgrp(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -51,18 +51,15 @@ func ParserChgrp(tokens []string) (string, error) {
 }
 
 // commandChgrp : Ejecuta el comando CHGRP
-func commandChgrp(chgrp *CHGRP, outputBuffer *strings.Builder) error {
+func commandChgrp(chgrp *CHGRP, user *structs.User, outputBuffer *strings.Builder) error {
 	fmt.Fprintln(outputBuffer, "======================= CHGRP =======================")
-	// Verificar si hay una sesión activa y si el usuario es root
-	if !globals.IsLoggedIn() {
-		return fmt.Errorf("no hay ninguna sesión activa")
-	}
-	if globals.UsuarioActual.Name != "root" {
-		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	// Verificar si hay una sesión activa y si el usuario tiene privilegios de administración
+	if err := globals.CheckAdminPrivileges(user); err != nil {
+		return err
 	}
 
 	// Verificar que la partición esté montada
-	partition, path, err := globals.GetMountedPartition(globals.UsuarioActual.Id)
+	partition, path, err := globals.GetMountedPartition(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
 	}
@@ -75,7 +72,7 @@ func commandChgrp(chgrp *CHGRP, outputBuffer *strings.Builder) error {
 	defer file.Close()
 
 	// Cargar el Superblock usando el descriptor de archivo
-	_, sb, _, err := globals.GetMountedPartitionRep(globals.UsuarioActual.Id)
+	_, sb, _, err := globals.GetMountedPartitionRep(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
 	}
@@ -94,6 +91,12 @@ func commandChgrp(chgrp *CHGRP, outputBuffer *strings.Builder) error {
 		return fmt.Errorf("error cambiando el grupo del usuario '%s': %v", chgrp.User, err)
 	}
 
+	// Registrar la operación en el journal
+	err = sb.AppendJournal(file, "chgrp", "/users.txt", chgrp.User+","+chgrp.Grp)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %v", err)
+	}
+
 	//Guardar el superbloque
 	err = sb.Encode(file, int64(partition.Part_start))
 	if err != nil {
@@ -197,7 +200,7 @@ func ChangeUserGroup(file *os.File, sb *structs.Superblock, usersInode *structs.
 		}
 
 		blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
-		var fileBlock structs.FileBlock
+		fileBlock := sb.NewFileBlock()
 
 		// Limpiar el contenido del bloque
 		fileBlock.ClearContent()
@@ -216,8 +219,8 @@ func ChangeUserGroup(file *os.File, sb *structs.Superblock, usersInode *structs.
 		return fmt.Errorf("error guardando los cambios en users.txt: %v", err)
 	}
 
-	// Actualizar el tamaño del archivo (i_size)
-	usersInode.I_size = int32(len(strings.Join(nuevoContenido, "\n")))
+	// Actualizar el tamaño del archivo (i_size), incluye el salto de línea final para que mkgrp y mkusr agreguen líneas nuevas
+	usersInode.I_size = int32(len(strings.Join(nuevoContenido, "\n") + "\n"))
 
 	// Actualizar tiempos de modificación y cambio
 	usersInode.UpdateMtime()
@@ -243,25 +246,35 @@ func WriteContentToBlocks(file *os.File, sb *structs.Superblock, usersInode *str
 	blockSize := int(sb.S_block_size)
 
 	// Escribir el contenido por bloques
+	written := 0
 	for i, blockIndex := range usersInode.I_block {
 		if blockIndex == -1 {
 			break // No hay más bloques asignados
 		}
 
 		// Dividir los datos en bloques de tamaño máximo
-		start := i * blockSize
-		end := start + blockSize
-		if end > len(data) {
-			end = len(data)
-		}
+		start := min(i*blockSize, len(data))
+		end := min(start+blockSize, len(data))
 
 		// Crear un bloque con el contenido correspondiente
-		var fileBlock structs.FileBlock
-		copy(fileBlock.B_content[:], data[start:end])
+		fileBlock := sb.NewFileBlock()
+		copy(fileBlock.B_content, data[start:end])
+
+		// Guardar el bloque en la partición
+		blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
+		err := fileBlock.Encode(file, blockOffset)
+		if err != nil {
+			return fmt.Errorf("error escribiendo el bloque %d: %w", blockIndex, err)
+		}
+		written = end
 
 		// Mostrar el bloque escrito para depuración
 		fmt.Printf("Escribiendo bloque %d: %s\n", blockIndex, string(fileBlock.B_content[:]))
 	}
 
+	// El contenido reorganizado debe caber en los bloques que ya tiene users.txt
+	if written < len(data) {
+		return fmt.Errorf("el contenido de users.txt no cabe en sus %d bloques", written/blockSize)
+	}
 	return nil
 }
@@ -18,7 +18,7 @@ type MKGRP struct {
 }
 
 // ParserMkgrp : Parseo de argumentos para el comando mkgrp y captura de los mensajes importantes
-func ParserMkgrp(tokens []string) (string, error) {
+func ParserMkgrp(tokens []string, user *structs.User) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
 
 	// Inicializar el comando MKGRP
@@ -40,7 +40,7 @@ func ParserMkgrp(tokens []string) (string, error) {
 	cmd.Name = param[1]
 
 	// Ejecutar la lógica del comando mkgrp
-	err := commandMkgrp(cmd, &outputBuffer)
+	err := commandMkgrp(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -49,18 +49,15 @@ func ParserMkgrp(tokens []string) (string, error) {
 	return outputBuffer.String(), nil
 }
 
-func commandMkgrp(mkgrp *MKGRP, outputBuffer *bytes.Buffer) error {
+func commandMkgrp(mkgrp *MKGRP, user *structs.User, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintln(outputBuffer, "======================= MKGRP =======================")
-	// Verificar si hay una sesión activa y si el usuario es root
-	if !globals.IsLoggedIn() {
-		return fmt.Errorf("no hay ninguna sesión activa")
-	}
-	if globals.UsuarioActual.Name != "root" {
-		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	// Verificar si hay una sesión activa y si el usuario tiene privilegios de administración
+	if err := globals.CheckAdminPrivileges(user); err != nil {
+		return err
 	}
 
 	// Verificar que la partición esté montada
-	_, path, err := globals.GetMountedPartition(globals.UsuarioActual.Id)
+	_, path, err := globals.GetMountedPartition(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
 	}
@@ -73,13 +70,13 @@ func commandMkgrp(mkgrp *MKGRP, outputBuffer *bytes.Buffer) error {
 	defer file.Close()
 
 	// Cargar el Superblock y la partición
-	mbr, sb, _, err := globals.GetMountedPartitionRep(globals.UsuarioActual.Id) //Id de la particion del usuario actual
+	mbr, sb, _, err := globals.GetMountedPartitionRep(user.Id) //Id de la particion del usuario actual
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
 	}
 
 	// Obtener la partición asociada al id
-	partition, err := mbr.GetPartitionByID(globals.UsuarioActual.Id)
+	partition, err := mbr.GetPartitionByID(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener la partición: %v", err)
 	}
@@ -123,6 +120,12 @@ func commandMkgrp(mkgrp *MKGRP, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error actualizando inodo de users.txt: %v", err)
 	}
 
+	// Registrar la operación en el journal
+	err = sb.AppendJournal(file, "mkgrp", "/users.txt", mkgrp.Name)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %v", err)
+	}
+
 	// Guardar el Superblock utilizando el Part_start como el offset
 	err = sb.Encode(file, int64(partition.Part_start)) // Guardar en Part_start
 	if err != nil {
@@ -27,7 +27,7 @@ func validateParamLength(param string, maxLength int, paramName string) error {
 }
 
 // ParserMkusr : Parseo de argumentos para el comando mkusr y captura de los mensajes importantes
-func ParserMkusr(tokens []string) (string, error) {
+func ParserMkusr(tokens []string, user *structs.User) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
 
 	// Inicializar el comando MKUSR
@@ -71,7 +71,7 @@ func ParserMkusr(tokens []string) (string, error) {
 	}
 
 	// Ejecutar la lógica del comando mkusr
-	err := commandMkusr(cmd, &outputBuffer)
+	err := commandMkusr(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -81,18 +81,15 @@ func ParserMkusr(tokens []string) (string, error) {
 }
 
 // commandMkusr : Ejecuta el comando MKUSR con captura de mensajes importantes en el buffer
-func commandMkusr(mkusr *MKUSR, outputBuffer *bytes.Buffer) error {
+func commandMkusr(mkusr *MKUSR, user *structs.User, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintln(outputBuffer, "======================= MKUSR =======================")
-	// Verificar si hay una sesión activa y si el usuario es root
-	if !globals.IsLoggedIn() {
-		return fmt.Errorf("no hay ninguna sesión activa")
-	}
-	if globals.UsuarioActual.Name != "root" {
-		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	// Verificar si hay una sesión activa y si el usuario tiene privilegios de administración
+	if err := globals.CheckAdminPrivileges(user); err != nil {
+		return err
 	}
 
 	// Verificar que la partición esté montada
-	_, path, err := globals.GetMountedPartition(globals.UsuarioActual.Id)
+	_, path, err := globals.GetMountedPartition(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
 	}
@@ -105,13 +102,13 @@ func commandMkusr(mkusr *MKUSR, outputBuffer *bytes.Buffer) error {
 	defer file.Close()
 
 	// Cargar el Superblock y la partición utilizando la función GetMountedPartitionRep
-	mbr, sb, _, err := globals.GetMountedPartitionRep(globals.UsuarioActual.Id)
+	mbr, sb, _, err := globals.GetMountedPartitionRep(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
 	}
 
 	// Obtener la partición montada
-	partition, err := mbr.GetPartitionByID(globals.UsuarioActual.Id)
+	partition, err := mbr.GetPartitionByID(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener la partición: %v", err)
 	}
@@ -137,7 +134,7 @@ func commandMkusr(mkusr *MKUSR, outputBuffer *bytes.Buffer) error {
 	}
 
 	// Crear un nuevo objeto de tipo User
-	usuario := structs.NewUser(fmt.Sprintf("%d", sb.S_inodes_count+1), mkusr.Grp, mkusr.User, mkusr.Pass)
+	usuario := structs.NewUser(fmt.Sprintf("%d", sb.UsedInodes()+1), mkusr.Grp, mkusr.User, mkusr.Pass)
 	fmt.Println(usuario.ToString())
 
 	// Insertar la nueva entrada en el archivo users.txt
@@ -152,6 +149,12 @@ func commandMkusr(mkusr *MKUSR, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error actualizando inodo de users.txt: %v", err)
 	}
 
+	// Registrar la operación en el journal
+	err = sb.AppendJournal(file, "mkusr", "/users.txt", mkusr.User+","+mkusr.Grp)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %v", err)
+	}
+
 	// Guardar el Superblock usando el Part_start como el offset
 	err = sb.Encode(file, int64(partition.Part_start))
 	if err != nil {
@@ -17,7 +17,7 @@ type RMGRP struct {
 }
 
 // ParserRmgrp : Parseo de argumentos para el comando rmgrp y captura de mensajes importantes
-func ParserRmgrp(tokens []string) (string, error) {
+func ParserRmgrp(tokens []string, user *structs.User) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
 
 	// Inicializar el comando RMGRP
@@ -39,7 +39,7 @@ func ParserRmgrp(tokens []string) (string, error) {
 	cmd.Name = param[1]
 
 	// Ejecutar la lógica del comando rmgrp
-	err := commandRmgrp(cmd, &outputBuffer)
+	err := commandRmgrp(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -49,18 +49,18 @@ func ParserRmgrp(tokens []string) (string, error) {
 }
 
 // commandRmgrp : Ejecuta el comando RMGRP con captura de mensajes importantes en el buffer
-func commandRmgrp(rmgrp *RMGRP, outputBuffer *bytes.Buffer) error {
+func commandRmgrp(rmgrp *RMGRP, user *structs.User, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintln(outputBuffer, "======================= RMGRP =======================")
-	// Verificar si hay una sesión activa y si el usuario es root
-	if !globals.IsLoggedIn() {
-		return fmt.Errorf("no hay ninguna sesión activa")
+	// Verificar si hay una sesión activa y si el usuario tiene privilegios de administración
+	if err := globals.CheckAdminPrivileges(user); err != nil {
+		return err
 	}
-	if globals.UsuarioActual.Name != "root" {
-		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	if rmgrp.Name == "root" {
+		return fmt.Errorf("el grupo root no puede ser eliminado")
 	}
 
 	// Verificar que la partición esté montada
-	_, path, err := globals.GetMountedPartition(globals.UsuarioActual.Id)
+	_, path, err := globals.GetMountedPartition(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
 	}
@@ -73,13 +73,18 @@ func commandRmgrp(rmgrp *RMGRP, outputBuffer *bytes.Buffer) error {
 	defer file.Close()
 
 	// Cargar el Superblock y la partición
-	mbr, sb, _, err := globals.GetMountedPartitionRep(globals.UsuarioActual.Id)
+	mbr, sb, _, err := globals.GetMountedPartitionRep(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
 	}
 
+	// El grupo administrador no se puede eliminar, antes se debe asignar otro con setadmin
+	if rmgrp.Name == sb.GetAdminGroup() {
+		return fmt.Errorf("el grupo '%s' es el grupo administrador y no puede ser eliminado, use setadmin para cambiarlo", rmgrp.Name)
+	}
+
 	// Obtener la partición montada
-	partition, err := mbr.GetPartitionByID(globals.UsuarioActual.Id)
+	partition, err := mbr.GetPartitionByID(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener la partición: %v", err)
 	}
@@ -110,6 +115,12 @@ func commandRmgrp(rmgrp *RMGRP, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error actualizando inodo de users.txt: %v", err)
 	}
 
+	// Registrar la operación en el journal
+	err = sb.AppendJournal(file, "rmgrp", "/users.txt", rmgrp.Name)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %v", err)
+	}
+
 	// Guardar el Superblock utilizando el Part_start como el offset
 	err = sb.Encode(file, int64(partition.Part_start)) // Usar Part_start como offset
 	if err != nil {
@@ -199,7 +210,7 @@ func UpdateEntityStateOrRemoveUsers(file *os.File, sb *structs.Superblock, users
 			}
 
 			blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
-			var fileBlock structs.FileBlock
+			fileBlock := sb.NewFileBlock()
 
 			// Limpiar el contenido del bloque
 			fileBlock.ClearContent()
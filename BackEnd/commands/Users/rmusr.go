@@ -17,7 +17,7 @@ type RMUSR struct {
 }
 
 // ParserRmusr : Parseo de argumentos para el comando rmusr y captura de mensajes importantes
-func ParserRmusr(tokens []string) (string, error) {
+func ParserRmusr(tokens []string, user *structs.User) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
 
 	// Inicializar el comando RMUSR
@@ -39,7 +39,7 @@ func ParserRmusr(tokens []string) (string, error) {
 	cmd.User = param[1]
 
 	// Ejecutar la lógica del comando rmusr
-	err := commandRmusr(cmd, &outputBuffer)
+	err := commandRmusr(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -49,18 +49,18 @@ func ParserRmusr(tokens []string) (string, error) {
 }
 
 // commandRmusr : Ejecuta el comando RMUSR y captura los mensajes importantes en un buffer
-func commandRmusr(rmusr *RMUSR, outputBuffer *bytes.Buffer) error {
+func commandRmusr(rmusr *RMUSR, user *structs.User, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintln(outputBuffer, "======================= RMUSR =======================")
-	// Verificar si hay una sesión activa y si el usuario es root
-	if !globals.IsLoggedIn() {
-		return fmt.Errorf("no hay ninguna sesión activa")
+	// Verificar si hay una sesión activa y si el usuario tiene privilegios de administración
+	if err := globals.CheckAdminPrivileges(user); err != nil {
+		return err
 	}
-	if globals.UsuarioActual.Name != "root" {
-		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	if rmusr.User == "root" {
+		return fmt.Errorf("el usuario root no puede ser eliminado")
 	}
 
 	// Verificar que la partición está montada
-	_, path, err := globals.GetMountedPartition(globals.UsuarioActual.Id)
+	_, path, err := globals.GetMountedPartition(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
 	}
@@ -73,13 +73,13 @@ func commandRmusr(rmusr *RMUSR, outputBuffer *bytes.Buffer) error {
 	defer file.Close()
 
 	// Cargar el Superblock y la partición usando el descriptor de archivo
-	mbr, sb, _, err := globals.GetMountedPartitionRep(globals.UsuarioActual.Id)
+	mbr, sb, _, err := globals.GetMountedPartitionRep(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
 	}
 
 	// Obtener la partición montada
-	partition, err := mbr.GetPartitionByID(globals.UsuarioActual.Id)
+	partition, err := mbr.GetPartitionByID(user.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo obtener la partición: %v", err)
 	}
@@ -110,6 +110,12 @@ func commandRmusr(rmusr *RMUSR, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error actualizando inodo de users.txt: %v", err)
 	}
 
+	// Registrar la operación en el journal
+	err = sb.AppendJournal(file, "rmusr", "/users.txt", rmusr.User)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %v", err)
+	}
+
 	// Guardar el Superblock utilizando el Part_start como el offset
 	err = sb.Encode(file, int64(partition.Part_start)) // Guardar en Part_start
 	if err != nil {
@@ -206,7 +212,7 @@ func escribirCambiosEnArchivo(file *os.File, sb *structs.Superblock, usersInode
 		}
 
 		blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
-		var fileBlock structs.FileBlock
+		fileBlock := sb.NewFileBlock()
 
 		// Limpiar el contenido del bloque
 		fileBlock.ClearContent()
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	structs "backend/Structs"
+	globals "backend/globals"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SETADMIN : Estructura para el comando SETADMIN
+type SETADMIN struct {
+	Grp string
+}
+
+// ParserSetadmin : Parseo de argumentos para el comando setadmin y captura de los mensajes importantes
+func ParserSetadmin(tokens []string, user *structs.User) (string, error) {
+	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
+
+	// Inicializar el comando SETADMIN
+	cmd := &SETADMIN{}
+
+	// Expresión regular para encontrar el parámetro -grp
+	re := regexp.MustCompile(`-grp=[^\s]+`)
+	matches := re.FindString(strings.Join(tokens, " "))
+
+	if matches == "" {
+		return "", fmt.Errorf("falta el parámetro -grp")
+	}
+
+	// Extraer el valor del parámetro -grp
+	param := strings.SplitN(matches, "=", 2)
+	if len(param) != 2 {
+		return "", fmt.Errorf("formato incorrecto para -grp")
+	}
+	cmd.Grp = param[1]
+
+	if err := validateParamLength(cmd.Grp, 10, "Grupo"); err != nil {
+		return "", err
+	}
+
+	// Ejecutar la lógica del comando setadmin
+	err := commandSetadmin(cmd, user, &outputBuffer)
+	if err != nil {
+		return "", err
+	}
+
+	// Retornar los mensajes importantes capturados en el buffer
+	return outputBuffer.String(), nil
+}
+
+// commandSetadmin : Registra en el superbloque el grupo con privilegios de administración
+func commandSetadmin(setadmin *SETADMIN, user *structs.User, outputBuffer *bytes.Buffer) error {
+	fmt.Fprintln(outputBuffer, "===================== SETADMIN =====================")
+	// Solo el usuario root puede delegar privilegios de administración
+	if !globals.IsLoggedIn(user) {
+		return fmt.Errorf("no hay ninguna sesión activa")
+	}
+	if user.Name != "root" {
+		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	}
+
+	// Verificar que la partición esté montada
+	partition, path, err := globals.GetMountedPartition(user.Id)
+	if err != nil {
+		return fmt.Errorf("no se puede encontrar la partición montada: %v", err)
+	}
+
+	// Abrir el archivo de la partición
+	file, err := os.OpenFile(path, os.O_RDWR, 0755)
+	if err != nil {
+		return fmt.Errorf("no se puede abrir el archivo de la partición: %v", err)
+	}
+	defer file.Close()
+
+	// Cargar el Superblock
+	_, sb, _, err := globals.GetMountedPartitionRep(user.Id)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
+	}
+
+	// Leer el inodo de users.txt
+	var usersInode structs.Inode
+	inodeOffset := int64(sb.S_inode_start + int32(binary.Size(usersInode))) // Posición del inodo de users.txt
+	err = usersInode.Decode(file, inodeOffset)
+	if err != nil {
+		return fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
+	}
+
+	// Verificar que el grupo exista y no esté eliminado
+	linea, err := globals.FindInUsersFile(file, sb, &usersInode, setadmin.Grp, "G")
+	if err != nil || strings.HasPrefix(linea, "0,") {
+		return fmt.Errorf("el grupo '%s' no existe o está eliminado", setadmin.Grp)
+	}
+
+	// Registrar el grupo administrador y guardar el superbloque
+	err = sb.SetAdminGroup(setadmin.Grp, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+	err = sb.Encode(file, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error guardando el Superblock: %v", err)
+	}
+
+	fmt.Fprintf(outputBuffer, "El grupo '%s' ahora tiene privilegios de administración\n", setadmin.Grp)
+	fmt.Fprintln(outputBuffer, "=====================================================")
+	return nil
+}
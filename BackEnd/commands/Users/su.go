@@ -0,0 +1,125 @@
+package commands
+
+import (
+	structs "backend/Structs"
+	globals "backend/globals"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SU : Estructura para el comando SU
+type SU struct {
+	Pass string // Contraseña del usuario root
+	Line string // Línea de comando a ejecutar como root
+}
+
+// ParserSu : Parseo de argumentos para el comando su, la línea restante se ejecuta como root con execute
+// user es el usuario de la sesión que ejecuta su, execute recibe el usuario con el que se ejecuta la línea
+func ParserSu(tokens []string, user *structs.User, execute func(string, *structs.User) (string, error)) (string, error) {
+	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes para el usuario
+
+	// Inicializar el comando SU
+	cmd := &SU{}
+
+	// El primer parámetro debe ser la contraseña de root, el resto es la línea a ejecutar
+	re := regexp.MustCompile(`^-pass=[^\s]+$`)
+	if len(tokens) == 0 || !re.MatchString(tokens[0]) {
+		return "", fmt.Errorf("falta el parámetro -pass")
+	}
+	cmd.Pass = strings.SplitN(tokens[0], "=", 2)[1]
+	cmd.Line = strings.Join(tokens[1:], " ")
+
+	if strings.TrimSpace(cmd.Line) == "" {
+		return "", fmt.Errorf("falta el comando a ejecutar como root")
+	}
+
+	// No se permite cambiar la sesión desde dentro de su
+	switch strings.ToLower(strings.Fields(cmd.Line)[0]) {
+	case "su", "login", "logout":
+		return "", fmt.Errorf("el comando '%s' no puede ejecutarse con su", strings.Fields(cmd.Line)[0])
+	}
+
+	// Ejecutar la lógica del comando su
+	err := commandSu(cmd, user, execute, &outputBuffer)
+	if err != nil {
+		return "", err
+	}
+
+	// Retornar los mensajes importantes capturados en el buffer
+	return outputBuffer.String(), nil
+}
+
+// commandSu : Verifica la contraseña de root y ejecuta una única línea con sus privilegios
+// La sesión no cambia, root solo se pasa a la línea que se ejecuta, así las solicitudes en paralelo siguen con su usuario
+func commandSu(su *SU, user *structs.User, execute func(string, *structs.User) (string, error), outputBuffer *bytes.Buffer) error {
+	fmt.Fprintln(outputBuffer, "========================= SU =========================")
+	if !globals.IsLoggedIn(user) {
+		return fmt.Errorf("no hay ninguna sesión activa")
+	}
+
+	// Buscar el usuario root en la partición del usuario actual
+	rootUser, err := findRootUser(user.Id)
+	if err != nil {
+		return err
+	}
+	if rootUser.Password != su.Pass {
+		return fmt.Errorf("contraseña de root incorrecta")
+	}
+	rootUser.Id = user.Id // Id guarda la partición de la sesión
+
+	fmt.Fprintf(outputBuffer, "Ejecutando como root: %s\n", su.Line)
+	result, err := execute(su.Line, rootUser)
+	if err != nil {
+		return err
+	}
+	outputBuffer.WriteString(result)
+	fmt.Fprintln(outputBuffer, "\n=====================================================")
+	return nil
+}
+
+// findRootUser : Obtiene el usuario root desde users.txt de la partición indicada
+func findRootUser(id string) (*structs.User, error) {
+	_, path, err := globals.GetMountedPartition(id)
+	if err != nil {
+		return nil, fmt.Errorf("no se puede encontrar la partición montada: %v", err)
+	}
+
+	// su no bloquea el disco al despacharse porque la línea que ejecuta lo bloquea por su cuenta
+	// Aquí solo se bloquea para lectura mientras se busca root
+	unlock := globals.RLockDisk(path)
+	defer unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se puede abrir el archivo de la partición: %v", err)
+	}
+	defer file.Close()
+
+	_, sb, _, err := globals.GetMountedPartitionRep(id)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo cargar el Superblock: %v", err)
+	}
+
+	// Leer el inodo de users.txt
+	var usersInode structs.Inode
+	inodeOffset := int64(sb.S_inode_start + int32(binary.Size(usersInode))) // Posición del inodo de users.txt
+	err = usersInode.Decode(file, inodeOffset)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
+	}
+
+	linea, err := globals.FindInUsersFile(file, sb, &usersInode, "root", "U")
+	if err != nil {
+		return nil, fmt.Errorf("el usuario root no existe en la partición")
+	}
+
+	usuario := crearUsuarioDesdeLinea(linea)
+	if usuario == nil {
+		return nil, fmt.Errorf("entrada de root mal formada en users.txt")
+	}
+	return usuario, nil
+}
@@ -3,23 +3,25 @@ package commands
 import (
 	structs "backend/Structs"
 	global "backend/globals"
-	utils "backend/utils"
 	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // CAT estructura que representa el comando CAT con sus parámetros
 type CAT struct {
-	files []string // Lista de archivos a leer
+	files  []string // Lista de archivos a leer
+	offset int      // Byte desde el que se empieza a leer
+	length int      // Cantidad de bytes a leer (-1 lee hasta el final)
 }
 
 // ParserCat parsea el comando cat y devuelve una instancia de CAT
-func ParserCat(tokens []string) (string, error) {
-	cmd := &CAT{}                 // Crea una nueva instancia de CAT
+func ParserCat(tokens []string, user *structs.User) (string, error) {
+	cmd := &CAT{length: -1}       // Crea una nueva instancia de CAT
 	var outputBuffer bytes.Buffer // Buffer para capturar mensajes importantes
 
 	// Expresión regular para capturar los archivos pasados como parámetros -file1, -file2, etc.
@@ -45,8 +47,23 @@ func ParserCat(tokens []string) (string, error) {
 		}
 	}
 
+	// Parámetros opcionales para leer solo un rango de bytes
+	reRange := regexp.MustCompile(`-(offset|length)=[^\s]+`)
+	for _, match := range reRange.FindAllString(strings.Join(tokens, " "), -1) {
+		kv := strings.SplitN(match, "=", 2)
+		value, err := strconv.Atoi(kv[1])
+		if err != nil || value < 0 {
+			return "", fmt.Errorf("el parámetro %s debe ser un número entero no negativo", kv[0])
+		}
+		if kv[0] == "-offset" {
+			cmd.offset = value
+		} else {
+			cmd.length = value
+		}
+	}
+
 	// Ejecutar el comando CAT
-	err := commandCat(cmd, &outputBuffer)
+	err := commandCat(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -54,15 +71,15 @@ func ParserCat(tokens []string) (string, error) {
 	return outputBuffer.String(), nil
 }
 
-func commandCat(cat *CAT, outputBuffer *bytes.Buffer) error {
+func commandCat(cat *CAT, user *structs.User, outputBuffer *bytes.Buffer) error {
 	fmt.Fprint(outputBuffer, "======================= CAT =======================\n")
 	// Verificar si hay un usuario logueado
-	if !global.IsLoggedIn() {
+	if !global.IsLoggedIn(user) {
 		return fmt.Errorf("no hay un usuario logueado")
 	}
 
 	// Obtener el ID de la partición desde el usuario logueado
-	idPartition := global.UsuarioActual.Id
+	idPartition := user.Id
 
 	// Obtener la partición montada asociada al usuario logueado
 	_, _, partitionPath, err := global.GetMountedPartitionSuperblock(idPartition)
@@ -82,7 +99,7 @@ func commandCat(cat *CAT, outputBuffer *bytes.Buffer) error {
 		fmt.Fprintf(outputBuffer, "Leyendo archivo: %s\n", filePath)
 
 		// Leer el contenido del archivo
-		content, err := readFileContent(filePath)
+		content, err := readFileContent(user.Id, filePath, cat.offset, cat.length)
 		if err != nil {
 			fmt.Fprintf(outputBuffer, "Error al leer el archivo %s: %v\n", filePath, err)
 			continue
@@ -97,33 +114,29 @@ func commandCat(cat *CAT, outputBuffer *bytes.Buffer) error {
 	return nil
 }
 
-// readFileContent busca el archivo en el sistema de archivos y lee su contenido
-func readFileContent(filePath string) (string, error) {
+// readFileContent busca el archivo en el sistema de archivos y lee su contenido desde offset hasta offset+length
+func readFileContent(idPartition string, filePath string, offset int, length int) (string, error) {
 	// Obtener el Superblock y la partición montada asociada
-	idPartition := global.UsuarioActual.Id
 	partitionSuperblock, _, partitionPath, err := global.GetMountedPartitionSuperblock(idPartition)
 	if err != nil {
 		return "", fmt.Errorf("error al obtener la partición montada: %v", err)
 	}
 
-	// Abrir el archivo de partición para leer
-	file, err := os.OpenFile(partitionPath, os.O_RDONLY, 0666)
+	// Abrir el archivo de partición en lectura/escritura para actualizar el I_atime
+	file, err := os.OpenFile(partitionPath, os.O_RDWR, 0666)
 	if err != nil {
 		return "", fmt.Errorf("error al abrir el archivo de partición: %v", err)
 	}
 	defer file.Close()
 
-	// Convertir el path del archivo en un array de carpetas
-	parentDirs, fileName := utils.GetParentDirectories(filePath)
-
 	// Buscar el archivo en el sistema de archivos
-	inodeIndex, err := findFileInode(file, partitionSuperblock, parentDirs, fileName)
+	inodeIndex, err := partitionSuperblock.ResolvePath(file, filePath)
 	if err != nil {
 		return "", fmt.Errorf("error al encontrar el archivo: %v", err)
 	}
 
 	// Leer el contenido del archivo
-	content, err := readFileFromInode(file, partitionSuperblock, inodeIndex)
+	content, err := readFileFromInode(file, partitionSuperblock, inodeIndex, offset, length)
 	if err != nil {
 		return "", fmt.Errorf("error al leer el contenido del archivo: %v", err)
 	}
@@ -131,106 +144,59 @@ func readFileContent(filePath string) (string, error) {
 	return content, nil
 }
 
-// directoryExists verifica si un directorio o archivo ya existe en el inodo dado
-func directoryExists(sb *structs.Superblock, file *os.File, inodeIndex int32, dirName string) (bool, int32, error) {
-	fmt.Printf("Verificando si el directorio o archivo '%s' existe en el inodo %d\n", dirName, inodeIndex) // Depuración
-
-	// Deserializar el inodo correspondiente
+// readFileFromInode lee exactamente I_size bytes de un archivo, o el rango [offset, offset+length) si se indica
+func readFileFromInode(file *os.File, sb *structs.Superblock, inodeIndex int32, offset int, length int) (string, error) {
 	inode := &structs.Inode{}
-	err := inode.Decode(file, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	inodeOffset := int64(sb.S_inode_start + (inodeIndex * sb.S_inode_size))
+	err := inode.Decode(file, inodeOffset)
 	if err != nil {
-		return false, -1, fmt.Errorf("error al deserializar inodo %d: %v", inodeIndex, err)
-	}
-
-	// Verificar si el inodo es de tipo carpeta (I_type == '0') para continuar
-	if inode.I_type[0] != '0' {
-		return false, -1, fmt.Errorf("el inodo %d no es una carpeta", inodeIndex)
-	}
-
-	// Iterar sobre los bloques del inodo para buscar el directorio o archivo
-	for _, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			break // Si no hay más bloques asignados, terminamos la búsqueda
-		}
-
-		// Deserializar el bloque de directorio
-		block := &structs.FolderBlock{}
-		err := block.Decode(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return false, -1, fmt.Errorf("error al deserializar bloque %d: %v", blockIndex, err)
-		}
-
-		// Iterar sobre los contenidos del bloque para verificar si el nombre coincide
-		for _, content := range block.B_content {
-			contentName := strings.Trim(string(content.B_name[:]), "\x00 ") // Convertir el nombre y eliminar los caracteres nulos
-			if strings.EqualFold(contentName, dirName) && content.B_inodo != -1 {
-				fmt.Printf("Directorio o archivo '%s' encontrado en inodo %d\n", dirName, content.B_inodo) // Depuración
-				return true, content.B_inodo, nil                                                          // Devolver true si el directorio/archivo fue encontrado
-			}
-		}
+		return "", fmt.Errorf("error al deserializar el inodo %d: %v", inodeIndex, err)
 	}
 
-	fmt.Printf("Directorio o archivo '%s' no encontrado en inodo %d\n", dirName, inodeIndex) // Depuración
-	return false, -1, nil                                                                    // No se encontró el directorio/archivo
-}
-
-// findFileInode busca el inodo de un archivo dado el path
-func findFileInode(file *os.File, sb *structs.Superblock, parentsDir []string, fileName string) (int32, error) {
-	// Empezar buscando en el inodo raíz
-	inodeIndex := int32(0)
-
-	// Navegar por los directorios padres para llegar al archivo
-	for len(parentsDir) > 0 {
-		dirName := parentsDir[0]
-		found, newInodeIndex, err := directoryExists(sb, file, inodeIndex, dirName)
-		if err != nil {
-			return -1, err
-		}
-		if !found {
-			return -1, fmt.Errorf("directorio '%s' no encontrado", dirName)
-		}
-		inodeIndex = newInodeIndex
-		parentsDir = parentsDir[1:]
+	if inode.I_type[0] != '1' {
+		return "", fmt.Errorf("el inodo %d no corresponde a un archivo", inodeIndex)
 	}
 
-	// Buscar el archivo en el último directorio
-	found, fileInodeIndex, err := directoryExists(sb, file, inodeIndex, fileName)
-	if err != nil {
-		return -1, err
+	// Calcular el rango de bytes a leer sin sobrepasar el tamaño del archivo
+	size := int(inode.I_size)
+	if offset > size {
+		return "", fmt.Errorf("el offset %d excede el tamaño del archivo (%d bytes)", offset, size)
 	}
-	if !found {
-		return -1, fmt.Errorf("archivo '%s' no encontrado", fileName)
+	end := size
+	if length >= 0 && offset+length < end {
+		end = offset + length
 	}
 
-	return fileInodeIndex, nil
-}
-
-// readFileFromInode lee el contenido de un archivo desde su inodo
-func readFileFromInode(file *os.File, sb *structs.Superblock, inodeIndex int32) (string, error) {
-	inode := &structs.Inode{}
-	err := inode.Decode(file, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	// Obtener los bloques de datos en orden, incluidos los de los bloques indirectos
+	blocks, err := sb.FileBlocks(file, inode)
 	if err != nil {
-		return "", fmt.Errorf("error al deserializar el inodo %d: %v", inodeIndex, err)
-	}
-
-	if inode.I_type[0] != '1' {
-		return "", fmt.Errorf("el inodo %d no corresponde a un archivo", inodeIndex)
+		return "", fmt.Errorf("error al obtener los bloques del inodo %d: %v", inodeIndex, err)
 	}
 
-	// Concatenar los bloques de contenido del archivo
+	// Leer solo los bloques que contienen el rango solicitado
+	blockSize := int(sb.S_block_size)
 	var contentBuilder strings.Builder
-	for _, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			break
-		}
+	for i := offset / blockSize; i < len(blocks) && i*blockSize < end; i++ {
+		blockIndex := blocks[i]
 
-		fileBlock := &structs.FileBlock{}
+		fileBlock := sb.NewFileBlock()
 		err := fileBlock.Decode(file, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
 			return "", fmt.Errorf("error al deserializar el bloque %d: %v", blockIndex, err)
 		}
 
-		contentBuilder.WriteString(string(fileBlock.B_content[:]))
+		// Recortar el bloque al rango solicitado
+		blockStart := i * blockSize
+		from := max(offset-blockStart, 0)
+		to := min(end-blockStart, blockSize)
+		contentBuilder.Write(fileBlock.B_content[from:to])
+	}
+
+	// Actualizar el tiempo de último acceso en el disco
+	inode.UpdateAtime()
+	err = inode.Encode(file, inodeOffset)
+	if err != nil {
+		return "", fmt.Errorf("error al actualizar el inodo %d: %v", inodeIndex, err)
 	}
 
 	return contentBuilder.String(), nil
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	structures "backend/Structs"
+	"backend/utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestFile formatea una imagen .mia temporal con el mismo código que mkfs y crea /f.txt con content
+// Devuelve el archivo de la imagen, el superbloque y el inodo de /f.txt
+func newTestFile(t *testing.T, content string) (*os.File, *structures.Superblock, int32) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "disco.mia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	// Misma distribución que mkfs: 64 inodos, 3 bloques de 64 bytes por inodo
+	const partStart = 512
+	sb := structures.NewSuperblock(partStart, 64, structures.DefaultBlockSize, structures.DefaultInodeRatio, 2)
+	err = file.Truncate(sb.End())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sb.Format(file, partStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := utils.SplitStringIntoChunks(content, int(sb.S_block_size))
+	if err := sb.CreateFile(file, "/f.txt", len(content), chunks, structures.FirstFit); err != nil {
+		t.Fatal(err)
+	}
+	inodeIndex, err := sb.ResolvePath(file, "/f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, sb, inodeIndex
+}
+
+func TestReadFileFromInode(t *testing.T) {
+	// 1000 bytes ocupan 16 bloques de 64, los últimos 4 quedan en el bloque indirecto simple
+	content := generateContent(990) + "abcdefghij"
+	file, sb, inodeIndex := newTestFile(t, content)
+
+	tests := []struct {
+		name    string
+		offset  int
+		length  int
+		want    string
+		wantErr bool
+	}{
+		{name: "archivo completo", offset: 0, length: -1, want: content},
+		{name: "dentro de un bloque", offset: 3, length: 5, want: content[3:8]},
+		{name: "cruza bloques", offset: 60, length: 10, want: content[60:70]},
+		{name: "bloques indirectos", offset: 770, length: 100, want: content[770:870]},
+		{name: "desde offset hasta el final", offset: 990, length: -1, want: "abcdefghij"},
+		{name: "length pasa el final", offset: 995, length: 50, want: "fghij"},
+		{name: "length cero", offset: 10, length: 0, want: ""},
+		{name: "offset en el final", offset: 1000, length: -1, want: ""},
+		{name: "offset pasa el final", offset: 1001, length: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readFileFromInode(file, sb, inodeIndex, tt.offset, tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readFileFromInode(%d, %d) error = %v, se esperaba error: %v", tt.offset, tt.length, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("readFileFromInode(%d, %d) = %q, se esperaba %q", tt.offset, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFileFromInodeFolder(t *testing.T) {
+	file, sb, _ := newTestFile(t, "x")
+	_, err := readFileFromInode(file, sb, structures.RootInode, 0, -1)
+	if err == nil {
+		t.Fatal("se leyó la carpeta raíz como un archivo")
+	}
+}
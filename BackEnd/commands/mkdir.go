@@ -3,7 +3,6 @@ package commands
 import (
 	structures "backend/Structs"
 	global "backend/globals"
-	utils "backend/utils"
 	"bytes"
 	"errors"
 	"fmt"
@@ -18,7 +17,7 @@ type MKDIR struct {
 	p    bool   // Opción -p (crea directorios padres si no existen)
 }
 
-func ParserMkdir(tokens []string) (string, error) {
+func ParserMkdir(tokens []string, user *structures.User) (string, error) {
 	cmd := &MKDIR{}               // Crea una nueva instancia de MKDIR
 	var outputBuffer bytes.Buffer // Buffer para capturar mensajes importantes
 
@@ -71,7 +70,7 @@ func ParserMkdir(tokens []string) (string, error) {
 	}
 
 	// Ejecutar el comando mkdir con captura de mensajes en el buffer
-	err := commandMkdir(cmd, &outputBuffer)
+	err := commandMkdir(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -80,14 +79,14 @@ func ParserMkdir(tokens []string) (string, error) {
 	return outputBuffer.String(), nil
 }
 
-func commandMkdir(mkdir *MKDIR, outputBuffer *bytes.Buffer) error {
+func commandMkdir(mkdir *MKDIR, user *structures.User, outputBuffer *bytes.Buffer) error {
 	// Verificar si hay un usuario logueado
-	if !global.IsLoggedIn() {
+	if !global.IsLoggedIn(user) {
 		return fmt.Errorf("no hay un usuario logueado")
 	}
 
 	// Obtener el ID de la partición desde el usuario logueado
-	idPartition := global.UsuarioActual.Id
+	idPartition := user.Id
 
 	// Obtener la partición montada asociada al usuario logueado
 	partitionSuperblock, mountedPartition, partitionPath, err := global.GetMountedPartitionSuperblock(idPartition)
@@ -112,6 +111,12 @@ func commandMkdir(mkdir *MKDIR, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error al crear el directorio: %w", err)
 	}
 
+	// Registrar la operación en el journal
+	err = partitionSuperblock.AppendJournal(file, "mkdir", mkdir.path, "")
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %w", err)
+	}
+
 	fmt.Fprintf(outputBuffer, "Directorio %s creado exitosamente\n", mkdir.path)
 	fmt.Fprintln(outputBuffer, "=====================================================")
 
@@ -120,21 +125,14 @@ func commandMkdir(mkdir *MKDIR, outputBuffer *bytes.Buffer) error {
 
 func createDirectory(dirPath string, createParents bool, sb *structures.Superblock, file *os.File, mountedPartition *structures.Partition) error {
 
-	// Obtener directorios padres y el destino del directorio
-	parentDirs, destDir := utils.GetParentDirectories(dirPath)
-
-	// Si el parámetro -p está habilitado (createParents == true), crear los directorios intermedios
+	// Si el parámetro -p está habilitado (createParents == true), crear los directorios intermedios que falten
+	// y no fallar si el directorio ya existe, si no el directorio padre debe existir
+	var err error
 	if createParents {
-		for _, parentDir := range parentDirs {
-			err := sb.CreateFolder(file, parentDirs, parentDir)
-			if err != nil {
-				return fmt.Errorf("error al crear el directorio padre '%s': %w", parentDir, err)
-			}
-		}
+		err = sb.CreateFolders(file, dirPath)
+	} else {
+		err = sb.CreateFolder(file, dirPath)
 	}
-
-	// Crear el directorio final
-	err := sb.CreateFolder(file, parentDirs, destDir)
 	if err != nil {
 		return fmt.Errorf("error al crear el directorio: %w", err)
 	}
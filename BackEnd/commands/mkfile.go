@@ -19,11 +19,11 @@ type MKFILE struct {
 	path string // Ruta del archivo
 	r    bool   // Opción recursiva
 	size int    // Tamaño del archivo
-	cont string // Contenido del archivo
+	cont string // Path de un archivo del host con el contenido, tiene prioridad sobre size
 }
 
 // ParserMkfile parsea el comando mkfile y devuelve una instancia de MKFILE
-func ParserMkfile(tokens []string) (string, error) {
+func ParserMkfile(tokens []string, user *structures.User) (string, error) {
 	cmd := &MKFILE{}              // Crea una nueva instancia de MKFILE
 	var outputBuffer bytes.Buffer // Buffer para capturar mensajes importantes
 
@@ -68,7 +68,7 @@ func ParserMkfile(tokens []string) (string, error) {
 			cmd.size = size
 		case "-cont":
 			if value == "" {
-				return "", errors.New("el contenido no puede estar vacío")
+				return "", errors.New("el path del contenido no puede estar vacío")
 			}
 			cmd.cont = value
 		default:
@@ -80,16 +80,8 @@ func ParserMkfile(tokens []string) (string, error) {
 		return "", errors.New("faltan parámetros requeridos: -path")
 	}
 
-	if cmd.size == 0 {
-		cmd.size = 0
-	}
-
-	if cmd.cont == "" {
-		cmd.cont = ""
-	}
-
 	// Crear el archivo con los parámetros proporcionados
-	err := commandMkfile(cmd, &outputBuffer)
+	err := commandMkfile(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -98,14 +90,14 @@ func ParserMkfile(tokens []string) (string, error) {
 	return outputBuffer.String(), nil
 }
 
-func commandMkfile(mkfile *MKFILE, outputBuffer *bytes.Buffer) error {
+func commandMkfile(mkfile *MKFILE, user *structures.User, outputBuffer *bytes.Buffer) error {
 	// Verificar si hay un usuario logueado
-	if !global.IsLoggedIn() {
+	if !global.IsLoggedIn(user) {
 		return fmt.Errorf("no hay un usuario logueado")
 	}
 
 	// Obtener el ID de la partición desde el usuario logueado
-	idPartition := global.UsuarioActual.Id
+	idPartition := user.Id
 
 	// Obtener la partición montada asociada al usuario logueado
 	partitionSuperblock, mountedPartition, partitionPath, err := global.GetMountedPartitionSuperblock(idPartition)
@@ -113,9 +105,23 @@ func commandMkfile(mkfile *MKFILE, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
 
-	// Generar el contenido del archivo si no se proporcionó
-	if mkfile.cont == "" {
-		mkfile.cont = generateContent(mkfile.size)
+	// El contenido se copia del archivo del host indicado en -cont, si no se indicó se genera según -size
+	content := generateContent(mkfile.size)
+	if mkfile.cont != "" {
+		// -cont no recibe el contenido literal, solo el path de un archivo del host
+		info, err := os.Stat(mkfile.cont)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("-cont espera el path de un archivo del host y '%s' no existe", mkfile.cont)
+		}
+		if err == nil && info.IsDir() {
+			return fmt.Errorf("-cont espera el path de un archivo del host y '%s' es una carpeta", mkfile.cont)
+		}
+
+		data, err := os.ReadFile(mkfile.cont)
+		if err != nil {
+			return fmt.Errorf("error al leer el contenido desde '%s': %w", mkfile.cont, err)
+		}
+		content = string(data)
 	}
 
 	// Abrir el archivo de partición para operar sobre él
@@ -134,9 +140,10 @@ func commandMkfile(mkfile *MKFILE, outputBuffer *bytes.Buffer) error {
 
 	// Verificar solo la existencia del directorio (sin incluir el archivo)
 	fmt.Fprintf(outputBuffer, "Verificando la existencia del directorio: %s\n", dirPath)
-	exists, _, err := directoryExists(partitionSuperblock, file, 0, dirPath) // Usamos el inodo raíz (0) para empezar la búsqueda
-	if err != nil {
-		return fmt.Errorf("error al verificar directorio: %w", err)
+	_, _, err = partitionSuperblock.ResolveParent(file, mkfile.path)
+	exists := !errors.Is(err, structures.ErrPathNotFound)
+	if err != nil && exists {
+		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
 
 	// Si -r está habilitado y el directorio no existe, creamos los directorios intermedios
@@ -148,11 +155,17 @@ func commandMkfile(mkfile *MKFILE, outputBuffer *bytes.Buffer) error {
 	}
 
 	// Crear el archivo usando el archivo de partición abierto
-	err = createFile(mkfile.path, mkfile.size, mkfile.cont, partitionSuperblock, file, mountedPartition, outputBuffer)
+	err = createFile(mkfile.path, content, partitionSuperblock, file, mountedPartition, outputBuffer)
 	if err != nil {
 		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
 
+	// Registrar la operación en el journal
+	err = partitionSuperblock.AppendJournal(file, "mkfile", mkfile.path, content)
+	if err != nil {
+		return fmt.Errorf("error registrando la operación en el journal: %w", err)
+	}
+
 	fmt.Fprintf(outputBuffer, "Archivo %s creado exitosamente\n", mkfile.path)
 	fmt.Fprintln(outputBuffer, "=====================================================")
 
@@ -161,25 +174,20 @@ func commandMkfile(mkfile *MKFILE, outputBuffer *bytes.Buffer) error {
 
 // generateContent genera una cadena de números del 0 al 9 hasta cumplir el tamaño ingresado
 func generateContent(size int) string {
-	content := ""
-	for len(content) < size {
-		content += "0123456789"
-	}
-	return content[:size] // Recorta la cadena al tamaño exacto
+	return strings.Repeat("0123456789", size/10+1)[:size] // Recorta la cadena al tamaño exacto
 }
 
 // createFile ahora usa el archivo de partición ya abierto
-func createFile(filePath string, size int, content string, sb *structures.Superblock, file *os.File, mountedPartition *structures.Partition, outputBuffer *bytes.Buffer) error {
+// El contenido puede ser binario, el tamaño del archivo es su largo en bytes
+func createFile(filePath string, content string, sb *structures.Superblock, file *os.File, mountedPartition *structures.Partition, outputBuffer *bytes.Buffer) error {
 	fmt.Fprintf(outputBuffer, "Creando archivo en la ruta: %s\n", filePath)
 
-	// Obtener los directorios padres y el destino
-	parentDirs, destDir := utils.GetParentDirectories(filePath)
 	// Obtener contenido por chunks
-	chunks := utils.SplitStringIntoChunks(content)
-	fmt.Fprintf(outputBuffer, "Contenido generado: %v\n", chunks)
+	chunks := utils.SplitStringIntoChunks(content, int(sb.S_block_size))
+	fmt.Fprintf(outputBuffer, "Contenido: %d bytes en %d bloques\n", len(content), len(chunks))
 
 	// Crear el archivo en el sistema de archivos
-	err := sb.CreateFile(file, parentDirs, destDir, size, chunks)
+	err := sb.CreateFile(file, filePath, len(content), chunks, mountedPartition.Part_fit[0])
 	if err != nil {
 		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
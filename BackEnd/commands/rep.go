@@ -1,12 +1,12 @@
 package commands
 
 import (
+	structs "backend/Structs"
 	global "backend/globals"
 	reports "backend/reps"
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 )
@@ -19,7 +19,7 @@ type REP struct {
 	path_file_ls string // Ruta del archivo ls (opcional)
 }
 
-func ParserRep(tokens []string) (string, error) {
+func ParserRep(tokens []string, user *structs.User) (string, error) {
 	var outputBuffer bytes.Buffer // Buffer para capturar los mensajes importantes
 
 	cmd := &REP{} // Crea una nueva instancia de REP
@@ -47,11 +47,14 @@ func ParserRep(tokens []string) (string, error) {
 			if value == "" {
 				return "", errors.New("el path no puede estar vacío")
 			}
+			// El formato del reporte se infiere de la extensión
+			if _, err := reports.OutputFormat(value); err != nil {
+				return "", err
+			}
 			cmd.path = value
 		case "-name":
-			validNames := []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "sb", "file", "ls"}
-			if !contains(validNames, value) {
-				return "", errors.New("nombre inválido, debe ser uno de los siguientes: mbr, disk, inode, block, bm_inode, bm_block, sb, file, ls")
+			if _, ok := reports.Lookup(value); !ok {
+				return "", fmt.Errorf("nombre inválido, debe ser uno de los siguientes: %s", strings.Join(reports.Names(), ", "))
 			}
 			cmd.name = value
 		case "-path_file_ls":
@@ -61,12 +64,12 @@ func ParserRep(tokens []string) (string, error) {
 		}
 	}
 
-	if cmd.id == "" || cmd.path == "" || cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -id, -path, -name")
+	if cmd.path == "" || cmd.name == "" {
+		return "", errors.New("faltan parámetros requeridos: -path, -name")
 	}
 
 	// Ejecutar el comando y capturar mensajes
-	err := commandRep(cmd, &outputBuffer)
+	err := commandRep(cmd, user, &outputBuffer)
 	if err != nil {
 		return "", err
 	}
@@ -74,101 +77,75 @@ func ParserRep(tokens []string) (string, error) {
 	return outputBuffer.String(), nil // Retorna los mensajes importantes al frontend
 }
 
-func contains(list []string, value string) bool {
-	for _, v := range list {
-		if v == value {
-			return true
+// HelpRep genera la ayuda del comando rep con los reportes registrados
+func HelpRep() string {
+	var builder strings.Builder
+	builder.WriteString("Reportes disponibles (rep -name=<reporte>):\n")
+	for _, report := range reports.Reports() {
+		fmt.Fprintf(&builder, "- %s: %s\n", report.Name(), report.Description())
+		fmt.Fprintf(&builder, "    Parámetros: %s", strings.Join(reports.RequiredParams(report), " "))
+		if report.Requirements().Filesystem {
+			builder.WriteString(" (requiere partición formateada)")
+		}
+		if report.Requirements().Login {
+			builder.WriteString(" (requiere sesión activa)")
 		}
+		builder.WriteString("\n")
 	}
-	return false
+	builder.WriteString("El formato de salida se infiere de la extensión de -path: png, jpg, svg, pdf, dot, txt o json\n")
+	return builder.String()
 }
 
-func commandRep(rep *REP, outputBuffer *bytes.Buffer) error {
-	// Obtener la partición montada
-	mountedMbr, mountedSb, mountedDiskPath, err := global.GetMountedPartitionRep(rep.id)
-	if err != nil {
-		return err
+func commandRep(rep *REP, user *structs.User, outputBuffer *bytes.Buffer) error {
+	report, ok := reports.Lookup(rep.name)
+	if !ok {
+		return fmt.Errorf("tipo de reporte no soportado: %s", rep.name)
 	}
 
-	file, err := os.Open(mountedDiskPath)
-	if err != nil {
-		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+	// Verificar lo que el reporte declara necesitar
+	requirements := report.Requirements()
+	if requirements.Login && !global.IsLoggedIn(user) {
+		return fmt.Errorf("el reporte %s requiere una sesión activa", rep.name)
+	}
+	if requirements.FilePath && rep.path_file_ls == "" {
+		return fmt.Errorf("el reporte %s requiere el parámetro -path_file_ls", rep.name)
 	}
-	defer file.Close()
-
-	// Mensaje de inicio de generación de reporte
-	fmt.Fprintf(outputBuffer, "Generando reporte '%s'...\n", rep.name)
-	fmt.Printf("Generando reporte '%s'...\n", rep.name) // Mensaje de depuración
 
-	// Switch para manejar diferentes tipos de reportes
-	switch rep.name {
-	case "mbr":
-		// Reporte del MBR
-		err = reports.ReportMBR(mountedMbr, rep.path, file)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte MBR: %v\n", err)
-			fmt.Printf("Error generando reporte MBR: %v\n", err) // Depuración
-			return err
-		}
-	case "disk":
-		// Reporte del Disco
-		err = reports.ReportDisk(mountedMbr, rep.path, mountedDiskPath)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte del disco: %v\n", err)
-			fmt.Printf("Error generando reporte del disco: %v\n", err) // Depuración
-			return err
-		}
-	case "inode":
-		// Reporte de Inodos
-		err = reports.ReportInode(mountedSb, mountedDiskPath, rep.path)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte de inodos: %v\n", err)
-			fmt.Printf("Error generando reporte de inodos: %v\n", err) // Depuración
-			return err
-		}
-	case "block":
-		// Reporte de Bloques
-		err = reports.ReportBlock(mountedSb, mountedDiskPath, rep.path)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte de bloques: %v\n", err)
-			fmt.Printf("Error generando reporte de bloques: %v\n", err) // Depuración
-			return err
-		}
-	case "bm_inode":
-		// Reporte del Bitmap de Inodos
-		err = reports.ReportBMInode(mountedSb, mountedDiskPath, rep.path)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte de bitmap de inodos: %v\n", err)
-			fmt.Printf("Error generando reporte de bitmap de inodos: %v\n", err) // Depuración
-			return err
+	ctx := &reports.ReportContext{Path: rep.path, FilePath: rep.path_file_ls}
+	if requirements.MountedPartition {
+		if rep.id == "" {
+			return fmt.Errorf("el reporte %s requiere el parámetro -id", rep.name)
 		}
-	case "bm_block":
-		// Reporte del Bitmap de Bloques
-		err = reports.ReportBMBlock(mountedSb, mountedDiskPath, rep.path)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte de bitmap de bloques: %v\n", err)
-			fmt.Printf("Error generando reporte de bitmap de bloques: %v\n", err) // Depuración
-			return err
-		}
-	case "sb":
-		// Reporte del Superbloque
-		err = reports.ReportSuperblock(mountedSb, mountedDiskPath, rep.path)
-		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte del superbloque: %v\n", err)
-			fmt.Printf("Error generando reporte del superbloque: %v\n", err) // Depuración
-			return err
+
+		// Obtener la partición montada, el superbloque solo si el reporte lo necesita
+		var err error
+		if requirements.Filesystem {
+			ctx.MBR, ctx.Superblock, ctx.DiskPath, err = global.GetMountedPartitionRep(rep.id)
+		} else {
+			ctx.MBR, ctx.DiskPath, err = global.GetMountedPartitionMBR(rep.id)
 		}
-	case "file":
-		// Reporte de Archivo
-		err = reports.ReportFile(mountedSb, mountedDiskPath, rep.path, rep.path_file_ls)
 		if err != nil {
-			fmt.Fprintf(outputBuffer, "Error generando reporte de archivo: %v\n", err)
-			fmt.Printf("Error generando reporte de archivo: %v\n", err) // Depuración
 			return err
 		}
-	// Agrega más casos para otros tipos de reportes
-	default:
-		return fmt.Errorf("tipo de reporte no soportado: %s", rep.name)
+	}
+
+	// Mensaje de inicio de generación de reporte
+	fmt.Fprintf(outputBuffer, "Generando reporte '%s'...\n", rep.name)
+	fmt.Printf("Generando reporte '%s'...\n", rep.name) // Mensaje de depuración
+
+	err := report.Generate(ctx)
+
+	// Sin Graphviz el reporte queda como SVG o DOT, no se considera un error
+	var graphvizMissing *reports.GraphvizMissingError
+	if errors.As(err, &graphvizMissing) {
+		fmt.Fprintf(outputBuffer, "Graphviz no está instalado, reporte '%s' generado en la ruta: %s\n", rep.name, graphvizMissing.File)
+		fmt.Printf("Graphviz no está instalado, reporte '%s' generado en la ruta: %s\n", rep.name, graphvizMissing.File) // Depuración
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(outputBuffer, "Error generando reporte '%s': %v\n", rep.name, err)
+		fmt.Printf("Error generando reporte '%s': %v\n", rep.name, err) // Depuración
+		return err
 	}
 
 	// Mensaje de éxito en la generación de reporte
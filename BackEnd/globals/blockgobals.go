@@ -17,7 +17,7 @@ func ReadFileBlocks(file *os.File, sb *structs.Superblock, inode *structs.Inode)
 		}
 
 		blockOffset := int64(sb.S_block_start + blockIndex*int32(sb.S_block_size))
-		var fileBlock structs.FileBlock
+		fileBlock := sb.NewFileBlock()
 
 		// Leer el bloque desde el archivo
 		err := fileBlock.Decode(file, blockOffset)
@@ -45,8 +45,8 @@ func WriteUsersBlocks(file *os.File, sb *structs.Superblock, inode *structs.Inod
 	// Combinar el contenido existente con el nuevo contenido
 	contenidoTotal := contenidoExistente + nuevoContenido
 
-	// Dividir el contenido total en bloques de tamaño BlockSize
-	blocks, err := structs.SplitContent(contenidoTotal)
+	// Dividir el contenido total en bloques del tamaño de bloque de la partición
+	blocks, err := structs.SplitContent(contenidoTotal, sb.S_block_size)
 	if err != nil {
 		return fmt.Errorf("error al dividir el contenido en bloques: %w", err)
 	}
@@ -63,7 +63,7 @@ func WriteUsersBlocks(file *os.File, sb *structs.Superblock, inode *structs.Inod
 
 		// Si el bloque actual en el inodo está vacío, asignar uno nuevo
 		if inode.I_block[index] == -1 {
-			newBlockIndex, err := sb.AssignNewBlock(file, inode, index)
+			newBlockIndex, err := sb.AllocateBlock(file)
 			if err != nil {
 				return fmt.Errorf("error asignando nuevo bloque: %w", err)
 			}
@@ -153,7 +153,7 @@ func InsertIntoUsersFile(file *os.File, sb *structs.Superblock, inode *structs.I
 		}
 
 		blockOffset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
-		var fileBlock structs.FileBlock
+		fileBlock := sb.NewFileBlock()
 
 		// Limpiar el contenido del bloque
 		fileBlock.ClearContent()
@@ -218,13 +218,13 @@ func AddEntryToUsersFile(file *os.File, sb *structs.Superblock, inode *structs.I
 
 // CreateGroup añade un nuevo grupo en el archivo users.txt
 func CreateGroup(file *os.File, sb *structs.Superblock, inode *structs.Inode, groupName string) error {
-	groupEntry := fmt.Sprintf("%d,G,%s", sb.S_inodes_count+1, groupName)
+	groupEntry := fmt.Sprintf("%d,G,%s", sb.UsedInodes()+1, groupName)
 	return AddEntryToUsersFile(file, sb, inode, groupEntry, groupName, "G")
 }
 
 // CreateUser añade un nuevo usuario en el archivo users.txt
 func CreateUser(file *os.File, sb *structs.Superblock, inode *structs.Inode, userName, userPassword, groupName string) error {
-	userEntry := fmt.Sprintf("%d,U,%s,%s,%s", sb.S_inodes_count+1, userName, groupName, userPassword)
+	userEntry := fmt.Sprintf("%d,U,%s,%s,%s", sb.UsedInodes()+1, userName, groupName, userPassword)
 	return AddEntryToUsersFile(file, sb, inode, userEntry, userName, "U")
 }
 
@@ -0,0 +1,130 @@
+package globals
+
+import (
+	"backend/utils"
+	"sort"
+	"sync"
+)
+
+// mountMutex protege la tabla de montajes y el registro de discos, el servidor atiende solicitudes en paralelo
+var mountMutex sync.RWMutex
+
+var (
+	// mountedPartitions relaciona el ID de cada partición montada con el path de su disco
+	mountedPartitions = make(map[string]string)
+	// registeredDisks guarda los paths de los discos creados con mkdisk, se eliminan con rmdisk
+	registeredDisks = make(map[string]bool)
+)
+
+// Los paths se guardan normalizados con utils.DiskKey, igual que en los candados de los discos
+
+// GetMountedPath devuelve el path del disco de la partición montada con el id, vacío si no está montada
+func GetMountedPath(id string) string {
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+	return mountedPartitions[id]
+}
+
+// MountedPartitions devuelve una copia de la tabla de montajes (ID -> path del disco)
+func MountedPartitions() map[string]string {
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+
+	mounted := make(map[string]string, len(mountedPartitions))
+	for id, path := range mountedPartitions {
+		mounted[id] = path
+	}
+	return mounted
+}
+
+// AddMountedPartition agrega una partición a la tabla de montajes, registra su disco y guarda el estado
+// Los discos creados antes de existir el registro se registran al montarlos
+func AddMountedPartition(id string, path string) error {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
+
+	path = utils.DiskKey(path)
+	mountedPartitions[id] = path
+	registeredDisks[path] = true
+	return saveMountState()
+}
+
+// RegisterDisk agrega un disco al registro y guarda el estado
+func RegisterDisk(path string) error {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
+
+	path = utils.DiskKey(path)
+	if registeredDisks[path] {
+		return nil
+	}
+	registeredDisks[path] = true
+	return saveMountState()
+}
+
+// UnregisterDisk elimina un disco del registro junto con sus particiones montadas y su letra
+// Devuelve los IDs de las particiones que se desmontaron
+func UnregisterDisk(path string) ([]string, error) {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
+
+	path = utils.DiskKey(path)
+	var unmounted []string
+	for id, mountedPath := range mountedPartitions {
+		if mountedPath == path {
+			delete(mountedPartitions, id)
+			unmounted = append(unmounted, id)
+		}
+	}
+	sort.Strings(unmounted)
+
+	delete(registeredDisks, path)
+	utils.RemoveLetter(path)
+	return unmounted, saveMountState()
+}
+
+// DiskPaths devuelve los paths de los discos registrados ordenados
+func DiskPaths() []string {
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+	return diskPaths()
+}
+
+// MountedIDs devuelve los IDs de las particiones montadas ordenados
+func MountedIDs() []string {
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+
+	ids := make([]string, 0, len(mountedPartitions))
+	for id := range mountedPartitions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// MountedIDsOnDisk devuelve los IDs ordenados de las particiones montadas del disco
+func MountedIDsOnDisk(path string) []string {
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+
+	path = utils.DiskKey(path)
+	var ids []string
+	for id, mountedPath := range mountedPartitions {
+		if mountedPath == path {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// diskPaths devuelve los paths de los discos registrados ordenados, requiere mountMutex tomado
+func diskPaths() []string {
+	paths := make([]string, 0, len(registeredDisks))
+	for path := range registeredDisks {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -3,21 +3,25 @@ package globals
 import (
 	structures "backend/Structs"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 )
 
 // Mi carnet
 const Carnet string = "06" // 202100106
 var (
-	// UsuarioActual guarda la información del usuario logueado actualmente
-	UsuarioActual     *structures.User  = nil
-	MountedPartitions map[string]string = make(map[string]string)
+	// usuarioActual guarda la información del usuario logueado actualmente
+	usuarioActual *structures.User = nil
+	// sessionMutex protege la sesión, el servidor atiende solicitudes en paralelo
+	sessionMutex sync.Mutex
 )
 
 // GetMountedPartitionSuperblock obtiene el SuperBlock de la partición montada con el id especificado
 func GetMountedPartitionSuperblock(id string) (*structures.Superblock, *structures.Partition, string, error) {
 	// Obtener el path de la partición montada
-	path := MountedPartitions[id]
+	path := GetMountedPath(id)
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
 	}
@@ -26,6 +30,8 @@ func GetMountedPartitionSuperblock(id string) (*structures.Superblock, *structur
 	if err != nil {
 		return nil, nil, "", err
 	}
+	defer file.Close()
+
 	// Crear una instancia de MBR
 	var mbr structures.MBR
 
@@ -50,13 +56,19 @@ func GetMountedPartitionSuperblock(id string) (*structures.Superblock, *structur
 		return nil, nil, "", err
 	}
 
+	// Verificar que la partición esté formateada antes de confiar en el superbloque
+	err = sb.Validate()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w (id %s)", err, id)
+	}
+
 	return &sb, partition, path, nil
 }
 
 // GetMountedPartition obtiene la partición montada con el id especificado
 func GetMountedPartition(id string) (*structures.Partition, string, error) {
 	// Obtener el path de la partición montada
-	path := MountedPartitions[id]
+	path := GetMountedPath(id)
 	if path == "" {
 		return nil, "", errors.New("la partición no está montada")
 	}
@@ -89,7 +101,7 @@ func GetMountedPartition(id string) (*structures.Partition, string, error) {
 // GetMountedPartitionRep obtiene el MBR y el SuperBlock de la partición montada con el id especificado
 func GetMountedPartitionRep(id string) (*structures.MBR, *structures.Superblock, string, error) {
 	// Obtener el path de la partición montada
-	path := MountedPartitions[id]
+	path := GetMountedPath(id)
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
 	}
@@ -125,18 +137,138 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.Superblock,
 		return nil, nil, "", err
 	}
 
+	// Verificar que la partición esté formateada antes de confiar en el superbloque
+	err = sb.Validate()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w (id %s)", err, id)
+	}
+
 	return &mbr, &sb, path, nil
 }
 
-// IsLoggedIn verifica si hay un usuario logueado actualmente
-func IsLoggedIn() bool {
-	return UsuarioActual != nil && UsuarioActual.Status
+// GetMountedPartitionMBR obtiene el MBR del disco de la partición montada, sin requerir que esté formateada
+func GetMountedPartitionMBR(id string) (*structures.MBR, string, error) {
+	// Obtener el path de la partición montada
+	path := GetMountedPath(id)
+	if path == "" {
+		return nil, "", errors.New("la partición no está montada")
+	}
+
+	// Abrir el archivo para leer el MBR
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	// Deserializar la estructura MBR desde el archivo
+	var mbr structures.MBR
+	err = mbr.Decode(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &mbr, path, nil
+}
+
+// IsLoggedIn verifica si el usuario con el que se ejecuta el comando tiene una sesión activa
+func IsLoggedIn(user *structures.User) bool {
+	return user != nil && user.Status
 }
 
-// Logout cierra la sesión del usuario actual
-func Logout() {
-	if UsuarioActual != nil {
-		UsuarioActual.Status = false // Cambiar el estado del usuario a no logueado
-		UsuarioActual = nil          // Limpiar la información del usuario
+// CheckAdminPrivileges verifica que el usuario sea root o pertenezca al grupo administrador de su partición
+func CheckAdminPrivileges(user *structures.User) error {
+	if !IsLoggedIn(user) {
+		return errors.New("no hay ninguna sesión activa")
+	}
+	if user.Name == "root" {
+		return nil
+	}
+
+	// Obtener el grupo administrador registrado en el superbloque de la partición
+	sb, _, path, err := GetMountedPartitionSuperblock(user.Id)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el Superblock: %v", err)
+	}
+
+	adminGroup := sb.GetAdminGroup()
+	if adminGroup == "" || user.Group != adminGroup {
+		return errors.New("solo el usuario root o los miembros del grupo administrador pueden ejecutar este comando")
+	}
+
+	// El grupo de la sesión se guardó en el login, se verifica en users.txt que no se haya eliminado después
+	active, err := isGroupActive(path, sb, adminGroup)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el archivo users.txt: %v", err)
+	}
+	if !active {
+		return fmt.Errorf("el grupo '%s' fue eliminado, no tiene privilegios de administración", adminGroup)
+	}
+	return nil
+}
+
+// isGroupActive indica si el grupo existe en users.txt y no está eliminado (GID distinto de 0)
+func isGroupActive(path string, sb *structures.Superblock, group string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	usersInode, err := sb.ResolvePath(file, "/users.txt")
+	if err != nil {
+		return false, err
+	}
+	var inode structures.Inode
+	err = inode.Decode(file, int64(sb.S_inode_start+usersInode*sb.S_inode_size))
+	if err != nil {
+		return false, err
+	}
+
+	linea, err := FindInUsersFile(file, sb, &inode, group, "G")
+	if err != nil {
+		return false, nil // El grupo no existe
+	}
+	return !strings.HasPrefix(linea, "0,"), nil
+}
+
+// CurrentUser devuelve una copia del usuario con sesión activa, nil si no hay sesión
+// Cada solicitud toma la copia una sola vez y la pasa a los comandos, así un login o logout en paralelo no la cambia a medias
+func CurrentUser() *structures.User {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	if !IsLoggedIn(usuarioActual) {
+		return nil
+	}
+	user := *usuarioActual
+	return &user
+}
+
+// StartSession inicia la sesión del usuario si no hay otra activa
+func StartSession(user *structures.User) error {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	// Se verifica de nuevo aquí porque otro login pudo terminar mientras se leía users.txt
+	if IsLoggedIn(usuarioActual) {
+		return errors.New("ya hay un usuario logueado, debe cerrar sesión primero")
+	}
+	session := *user
+	session.Status = true
+	usuarioActual = &session
+	return nil
+}
+
+// Logout cierra la sesión del usuario actual y devuelve el usuario que la tenía, nil si no había sesión
+func Logout() *structures.User {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	if !IsLoggedIn(usuarioActual) {
+		return nil
 	}
+	user := usuarioActual
+	usuarioActual = nil // Limpiar la información del usuario
+	return user
 }
@@ -0,0 +1,42 @@
+package globals
+
+import (
+	"backend/utils"
+	"sync"
+)
+
+// diskLocks guarda un candado de lectura/escritura por cada disco, identificado por su path
+// Los comandos que solo leen el disco pueden ejecutarse en paralelo, los que escriben lo usan en exclusiva
+var (
+	diskLocksMutex sync.Mutex
+	diskLocks      = make(map[string]*sync.RWMutex)
+)
+
+// diskLock devuelve el candado del disco, creándolo si no existe
+func diskLock(path string) *sync.RWMutex {
+	diskLocksMutex.Lock()
+	defer diskLocksMutex.Unlock()
+
+	// El mismo disco puede escribirse con paths distintos, por ejemplo con "./" o "//"
+	key := utils.DiskKey(path)
+	lock, exists := diskLocks[key]
+	if !exists {
+		lock = &sync.RWMutex{}
+		diskLocks[key] = lock
+	}
+	return lock
+}
+
+// LockDisk bloquea el disco para escritura y devuelve la función que lo libera
+func LockDisk(path string) func() {
+	lock := diskLock(path)
+	lock.Lock()
+	return lock.Unlock
+}
+
+// RLockDisk bloquea el disco para lectura y devuelve la función que lo libera
+func RLockDisk(path string) func() {
+	lock := diskLock(path)
+	lock.RLock()
+	return lock.RUnlock
+}
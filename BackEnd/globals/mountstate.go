@@ -0,0 +1,138 @@
+package globals
+
+import (
+	structures "backend/Structs"
+	"backend/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// MountStateFile es el archivo donde se guardan los discos registrados, las particiones montadas y las letras de los discos
+// Permite reconstruir la tabla de montajes al reiniciar el servidor
+var MountStateFile = "mount_state.json"
+
+// mountState es el contenido del archivo de estado de montajes
+type mountState struct {
+	Disks             []string          `json:"disks"`              // Paths de los discos registrados
+	MountedPartitions map[string]string `json:"mounted_partitions"` // ID de la partición -> path del disco
+	Letters           utils.LetterState `json:"letters"`            // Letras asignadas a los discos
+}
+
+// saveMountState guarda los discos registrados, la tabla de montajes y las letras de los discos en MountStateFile
+// Requiere mountMutex tomado para escritura, así dos solicitudes no escriben el archivo al mismo tiempo
+func saveMountState() error {
+	state := mountState{
+		Disks:             diskPaths(),
+		MountedPartitions: mountedPartitions,
+		Letters:           utils.ExportLetters(),
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al serializar el estado de montajes: %w", err)
+	}
+
+	// Escribir en un archivo temporal y renombrarlo para no dejar un estado a medias
+	tmpPath := MountStateFile + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error al escribir el estado de montajes: %w", err)
+	}
+	err = os.Rename(tmpPath, MountStateFile)
+	if err != nil {
+		return fmt.Errorf("error al reemplazar el estado de montajes: %w", err)
+	}
+	return nil
+}
+
+// LoadMountState reconstruye la tabla de montajes desde MountStateFile
+// Solo se restauran las particiones cuyo disco sigue existiendo y cuyo MBR conserva el mismo Part_id
+// Devuelve los IDs restaurados
+func LoadMountState() ([]string, error) {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
+
+	data, err := os.ReadFile(MountStateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // No hay montajes guardados
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el estado de montajes: %w", err)
+	}
+
+	var state mountState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("error al interpretar el estado de montajes %s: %w", MountStateFile, err)
+	}
+
+	// Las letras se restauran completas para que los discos conserven su letra aunque no tengan montajes
+	if state.Letters.PathToLetter == nil {
+		state.Letters.PathToLetter = make(map[string]string)
+	}
+	err = utils.RestoreLetters(state.Letters)
+	if err != nil {
+		return nil, fmt.Errorf("error al restaurar las letras de los discos: %w", err)
+	}
+
+	// Los discos que ya no existen se descartan del registro
+	disks := make(map[string]bool)
+	for _, path := range state.Disks {
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("Disco %s descartado: %v\n", path, err) // Depuración
+			continue
+		}
+		disks[utils.DiskKey(path)] = true
+
+		// Completar las escrituras que quedaron a medias si el servidor se detuvo durante un comando
+		recovered, err := utils.RecoverTransaction(path)
+		if err != nil {
+			fmt.Printf("No se pudo recuperar el disco %s: %v\n", path, err) // Depuración
+		} else if recovered {
+			fmt.Printf("Disco %s recuperado desde su registro de transacción\n", path) // Depuración
+		}
+	}
+	registeredDisks = disks
+
+	var restored []string
+	mounted := make(map[string]string)
+	for id, path := range state.MountedPartitions {
+		err = verifyMountedPartition(id, path)
+		if err != nil {
+			fmt.Printf("Montaje %s descartado: %v\n", id, err) // Depuración
+			continue
+		}
+		mounted[id] = utils.DiskKey(path)
+		restored = append(restored, id)
+		registeredDisks[utils.DiskKey(path)] = true // Discos montados antes de existir el registro
+	}
+	mountedPartitions = mounted
+
+	sort.Strings(restored)
+	return restored, nil
+}
+
+// verifyMountedPartition comprueba que el disco exista y que su MBR tenga una partición montada con el id
+func verifyMountedPartition(id string, path string) error {
+	if path == "" {
+		return errors.New("path vacío")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	err = mbr.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = mbr.GetPartitionByID(id)
+	return err
+}
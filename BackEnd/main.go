@@ -2,6 +2,7 @@ package main
 
 import (
 	analyzer "backend/Analyzer" // Importa el paquete "analyzer" desde el directorio "backend/analyzer"
+	"backend/globals"           // Importa el paquete "globals" para restaurar las particiones montadas
 	"fmt"
 	"log"     // Importa el paquete "log" para registrar mensajes de error
 	"strings" // Importa el paquete "strings" para manipulación de cadenas
@@ -11,6 +12,15 @@ import (
 )
 
 func main() {
+	// Reconstruir las particiones montadas antes del último reinicio
+	restored, err := globals.LoadMountState()
+	if err != nil {
+		log.Printf("No se pudo restaurar el estado de montajes: %v", err)
+	}
+	if len(restored) > 0 {
+		log.Printf("Particiones montadas restauradas: %s", strings.Join(restored, ", "))
+	}
+
 	// Crear una nueva instancia de Fiber
 	app := fiber.New()
 
@@ -0,0 +1,278 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"backend/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formatos de salida soportados, se infieren a partir de la extensión de -path
+var supportedFormats = map[string]string{
+	".png":  "png",
+	".jpg":  "jpg",
+	".jpeg": "jpg",
+	".svg":  "svg",
+	".pdf":  "pdf",
+	".dot":  "dot",
+	".txt":  "txt",
+	".json": "json",
+}
+
+// GraphvizMissingError indica que Graphviz no está instalado y se generó un archivo alternativo
+type GraphvizMissingError struct {
+	File string // Ruta del archivo generado en su lugar (.svg para tablas, .dot para grafos)
+}
+
+func (e *GraphvizMissingError) Error() string {
+	return fmt.Sprintf("Graphviz no está instalado, se generó el archivo: %s", e.File)
+}
+
+// reportOutput agrupa las representaciones de un reporte, cada formato usa la que le corresponde
+type reportOutput struct {
+	dot  string      // Contenido DOT para Graphviz (png, jpg, svg, pdf, dot)
+	text string      // Representación en texto plano (txt), si está vacía se genera a partir de data
+	data interface{} // Datos del reporte serializables a JSON (json)
+
+	// Tablas del reporte para el renderizador SVG nativo, solo en los reportes tipo tabla
+	tables []*svgTable
+}
+
+// OutputFormat obtiene el formato de salida a partir de la extensión de la ruta
+func OutputFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "png", nil // Sin extensión se mantiene el comportamiento original
+	}
+	format, ok := supportedFormats[ext]
+	if !ok {
+		return "", fmt.Errorf("extensión '%s' no soportada, debe ser una de: png, jpg, svg, pdf, dot, txt, json", ext)
+	}
+	return format, nil
+}
+
+// writeReport escribe el reporte en la ruta indicada usando el formato inferido de su extensión
+func writeReport(path string, out reportOutput) error {
+	format, err := OutputFormat(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(out.data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error al serializar el reporte a JSON: %v", err)
+		}
+		return writeTextFile(path, string(content)+"\n")
+	case "txt":
+		text := out.text
+		if text == "" {
+			text, err = renderText(out.data)
+			if err != nil {
+				return err
+			}
+		}
+		return writeTextFile(path, text)
+	case "dot":
+		return writeDotFile(path, out.dot)
+	case "svg":
+		// Las tablas se dibujan sin Graphviz
+		if len(out.tables) > 0 {
+			return writeTextFile(path, renderSvg(out.tables))
+		}
+	}
+
+	// Formatos de imagen: escribir el DOT junto a la imagen y ejecutar Graphviz
+	dotFileName, outputImage := utils.GetFileNames(path)
+	if !graphvizAvailable() {
+		// Sin Graphviz las tablas se generan como SVG y los grafos quedan como DOT
+		if len(out.tables) > 0 {
+			svgFileName := strings.TrimSuffix(dotFileName, ".dot") + ".svg"
+			err = writeTextFile(svgFileName, renderSvg(out.tables))
+			if err != nil {
+				return err
+			}
+			return &GraphvizMissingError{File: svgFileName}
+		}
+		err = writeDotFile(dotFileName, out.dot)
+		if err != nil {
+			return err
+		}
+		return &GraphvizMissingError{File: dotFileName}
+	}
+
+	err = writeDotFile(dotFileName, out.dot)
+	if err != nil {
+		return err
+	}
+	return runGraphviz(format, dotFileName, outputImage)
+}
+
+// graphvizAvailable indica si el ejecutable dot de Graphviz está instalado
+func graphvizAvailable() bool {
+	_, err := exec.LookPath("dot")
+	return err == nil
+}
+
+// runGraphviz genera la imagen con Graphviz a partir del archivo DOT
+func runGraphviz(format string, dotFileName string, outputImage string) error {
+	cmd := exec.Command("dot", "-T"+format, dotFileName, "-o", outputImage)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error al ejecutar Graphviz: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeTextFile escribe contenido de texto en un archivo
+func writeTextFile(path string, content string) error {
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("error al escribir el archivo de reporte: %v", err)
+	}
+	return nil
+}
+
+// renderText convierte los datos del reporte a texto plano con formato clave: valor
+func renderText(data interface{}) (string, error) {
+	// Pasar por JSON para respetar los nombres de los campos del reporte
+	content, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("error al serializar el reporte: %v", err)
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber() // Conservar los enteros sin notación científica
+	err = decoder.Decode(&generic)
+	if err != nil {
+		return "", fmt.Errorf("error al convertir el reporte a texto: %v", err)
+	}
+
+	var builder strings.Builder
+	writeTextValue(&builder, generic, 0)
+	return builder.String(), nil
+}
+
+// writeTextValue escribe un valor genérico con la sangría indicada
+func writeTextValue(builder *strings.Builder, value interface{}, level int) {
+	indent := strings.Repeat("  ", level)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch v[key].(type) {
+			case map[string]interface{}, []interface{}:
+				fmt.Fprintf(builder, "%s%s:\n", indent, key)
+				writeTextValue(builder, v[key], level+1)
+			default:
+				fmt.Fprintf(builder, "%s%s: %v\n", indent, key, v[key])
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			fmt.Fprintf(builder, "%s- [%d]\n", indent, i)
+			writeTextValue(builder, item, level+1)
+		}
+	default:
+		fmt.Fprintf(builder, "%s%v\n", indent, v)
+	}
+}
+
+// textDot genera un DOT con el texto del reporte, para los reportes que son texto plano
+func textDot(title string, text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+	return fmt.Sprintf(`digraph G {
+		node [shape=plaintext, fontname="Courier"];
+		report [label=<
+			<table border="0" cellborder="1" cellspacing="0" cellpadding="6" bgcolor="#FFFDE7">
+				<tr><td bgcolor="#4CAF50"><b>%s</b></td></tr>
+				<tr><td align="left" balign="left">%s</td></tr>
+			</table>>];
+	}`, html.EscapeString(title), strings.Join(lines, "<br/>"))
+}
+
+// inodeJSON representa un inodo en los reportes JSON
+type inodeJSON struct {
+	Index  int32   `json:"index"`
+	Uid    int32   `json:"uid"`
+	Gid    int32   `json:"gid"`
+	Size   int32   `json:"size"`
+	Atime  string  `json:"atime"`
+	Ctime  string  `json:"ctime"`
+	Mtime  string  `json:"mtime"`
+	Type   string  `json:"type"`
+	Perm   string  `json:"perm"`
+	Blocks []int32 `json:"blocks"`
+}
+
+// newInodeJSON convierte un inodo a su representación JSON
+func newInodeJSON(inodeIndex int32, inode *structs.Inode) inodeJSON {
+	return inodeJSON{
+		Index:  inodeIndex,
+		Uid:    inode.I_uid,
+		Gid:    inode.I_gid,
+		Size:   inode.I_size,
+		Atime:  time.Unix(int64(inode.I_atime), 0).Format(time.RFC3339),
+		Ctime:  time.Unix(int64(inode.I_ctime), 0).Format(time.RFC3339),
+		Mtime:  time.Unix(int64(inode.I_mtime), 0).Format(time.RFC3339),
+		Type:   string(inode.I_type[:]),
+		Perm:   string(inode.I_perm[:]),
+		Blocks: inode.I_block[:],
+	}
+}
+
+// folderEntryJSON representa una entrada de un bloque de carpeta en los reportes JSON
+type folderEntryJSON struct {
+	Name  string `json:"name"`
+	Inode int32  `json:"inode"`
+}
+
+// blockJSON representa un bloque de carpeta, archivo o apuntadores en los reportes JSON
+type blockJSON struct {
+	Index    int32             `json:"index"`
+	Type     string            `json:"type"`
+	Entries  []folderEntryJSON `json:"entries,omitempty"`
+	Content  string            `json:"content,omitempty"`
+	Pointers []int32           `json:"pointers,omitempty"`
+}
+
+// newFolderBlockJSON convierte un bloque de carpeta a su representación JSON
+func newFolderBlockJSON(blockIndex int32, block *structs.FolderBlock) blockJSON {
+	data := blockJSON{Index: blockIndex, Type: "carpeta"}
+	for _, content := range block.B_content {
+		data.Entries = append(data.Entries, folderEntryJSON{Name: cleanBlockName(content.B_name), Inode: content.B_inodo})
+	}
+	return data
+}
+
+// newFileBlockJSON convierte un bloque de archivo a su representación JSON
+func newFileBlockJSON(blockIndex int32, block *structs.FileBlock) blockJSON {
+	return blockJSON{Index: blockIndex, Type: "archivo", Content: block.GetContent()}
+}
+
+// newPointerBlockJSON convierte un bloque de apuntadores a su representación JSON
+func newPointerBlockJSON(blockIndex int32, block *structs.PointerBlock) blockJSON {
+	return blockJSON{Index: blockIndex, Type: "apuntadores", Pointers: block.B_pointers[:]}
+}
+
+// edgeJSON representa una conexión entre nodos de los reportes de grafo
+type edgeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
@@ -0,0 +1,210 @@
+package reps
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "/tmp/reporte", want: "png"}, // Sin extensión se genera png
+		{path: "/tmp/reporte.png", want: "png"},
+		{path: "/tmp/reporte.JPG", want: "jpg"},
+		{path: "/tmp/reporte.jpeg", want: "jpg"},
+		{path: "/tmp/reporte.svg", want: "svg"},
+		{path: "/tmp/reporte.pdf", want: "pdf"},
+		{path: "/tmp/reporte.dot", want: "dot"},
+		{path: "/tmp/reporte.txt", want: "txt"},
+		{path: "/tmp/reporte.json", want: "json"},
+		{path: "/tmp/carpeta.png/reporte.json", want: "json"},
+		{path: "/tmp/reporte.bmp", wantErr: true},
+		{path: "/tmp/reporte.png.gz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := OutputFormat(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OutputFormat(%q) error = %v, se esperaba error: %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("OutputFormat(%q) = %q, se esperaba %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteReportWithoutGraphviz(t *testing.T) {
+	// Sin dot en el PATH Graphviz no está disponible
+	t.Setenv("PATH", "")
+	if graphvizAvailable() {
+		t.Fatal("se encontró dot con el PATH vacío")
+	}
+
+	table := newSvgTable("TABLA", "#4CAF50", 2)
+	table.addField("campo", "valor", "")
+	const dot = "digraph G { a -> b }"
+
+	tests := []struct {
+		name        string
+		file        string // Archivo pedido en -path
+		tables      bool   // El reporte es de tipo tabla
+		wantFile    string // Archivo que se genera
+		wantMissing bool   // Se informa que falta Graphviz
+		wantContent string // Parte del contenido del archivo generado
+	}{
+		{name: "tabla en png usa svg", file: "r.png", tables: true, wantFile: "r.svg", wantMissing: true, wantContent: "<svg"},
+		{name: "grafo en png usa dot", file: "r.png", wantFile: "r.dot", wantMissing: true, wantContent: dot},
+		{name: "grafo en pdf usa dot", file: "r.pdf", wantFile: "r.dot", wantMissing: true, wantContent: dot},
+		{name: "tabla en svg sin Graphviz", file: "r.svg", tables: true, wantFile: "r.svg", wantContent: "<svg"},
+		{name: "grafo en svg usa dot", file: "r.svg", wantFile: "r.dot", wantMissing: true, wantContent: dot},
+		{name: "dot", file: "r.dot", tables: true, wantFile: "r.dot", wantContent: dot},
+		{name: "json", file: "r.json", tables: true, wantFile: "r.json", wantContent: `"campo": "valor"`},
+		{name: "txt", file: "r.txt", tables: true, wantFile: "r.txt", wantContent: "valor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			out := reportOutput{dot: dot, data: map[string]string{"campo": "valor"}}
+			if tt.tables {
+				out.tables = []*svgTable{table}
+			}
+
+			err := writeReport(filepath.Join(dir, tt.file), out)
+			var missing *GraphvizMissingError
+			if errors.As(err, &missing) != tt.wantMissing {
+				t.Fatalf("writeReport() error = %v, se esperaba que faltara Graphviz: %v", err, tt.wantMissing)
+			}
+			if err != nil && !tt.wantMissing {
+				t.Fatal(err)
+			}
+			if tt.wantMissing && missing.File != filepath.Join(dir, tt.wantFile) {
+				t.Fatalf("GraphvizMissingError.File = %q, se esperaba %q", missing.File, filepath.Join(dir, tt.wantFile))
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, tt.wantFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tt.wantContent) {
+				t.Fatalf("%s no contiene %q:\n%s", tt.wantFile, tt.wantContent, content)
+			}
+		})
+	}
+}
+
+func TestRenderSvg(t *testing.T) {
+	first := newSvgTable("PRIMERA <tabla> & más", "#4CAF50", 2)
+	first.addField("corto", "un valor bastante más largo que la etiqueta", "#FFF9C4")
+	first.addField("línea", "con\nsalto", "")
+	second := newSvgTable("SEGUNDA", "#2196F3", 3)
+	second.addRow(svgCell{text: "a"}, svgCell{text: "b"}, svgCell{text: "c"})
+
+	svg := renderSvg([]*svgTable{first, second})
+
+	// El documento debe ser XML válido, con el texto escapado
+	var root struct {
+		XMLName xml.Name
+		Width   float64 `xml:"width,attr"`
+		Height  float64 `xml:"height,attr"`
+	}
+	if err := xml.Unmarshal([]byte(svg), &root); err != nil {
+		t.Fatalf("el SVG no es XML válido: %v\n%s", err, svg)
+	}
+	if root.XMLName.Local != "svg" {
+		t.Fatalf("el elemento raíz es %q", root.XMLName.Local)
+	}
+
+	var texts []string
+	rects := 0
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "rect":
+				rects++
+			case "text":
+				var text string
+				if err := decoder.DecodeElement(&text, &start); err != nil {
+					t.Fatal(err)
+				}
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	// Una celda es un rectángulo con su texto, más el fondo del documento
+	wantTexts := []string{"PRIMERA <tabla> & más", "corto", "un valor bastante más largo que la etiqueta", "línea", "con salto", "SEGUNDA", "a", "b", "c"}
+	if !slices.Equal(texts, wantTexts) {
+		t.Fatalf("textos del SVG = %q, se esperaba %q", texts, wantTexts)
+	}
+	if rects != len(wantTexts)+1 {
+		t.Fatalf("el SVG tiene %d rectángulos, se esperaban %d", rects, len(wantTexts)+1)
+	}
+
+	// El documento alcanza para la tabla más ancha y las filas de ambas tablas
+	if first.width() < textWidth("corto")+textWidth("un valor bastante más largo que la etiqueta") {
+		t.Fatalf("la primera tabla mide %.0f, no alcanza para su fila más larga", first.width())
+	}
+	if got, want := fmt.Sprintf("%.0f", root.Width), fmt.Sprintf("%.0f", first.width()+2*svgMargin); got != want {
+		t.Fatalf("ancho del SVG = %s, se esperaba %s", got, want)
+	}
+	wantHeight := float64(5*svgRowHeight + svgTableGap + 2*svgMargin)
+	if root.Height != wantHeight {
+		t.Fatalf("alto del SVG = %.0f, se esperaba %.0f", root.Height, wantHeight)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	want := []string{"block", "bm_block", "bm_inode", "disk", "file", "inode", "journaling", "ls", "mbr", "sb", "tree"}
+	if names := Names(); !slices.Equal(names, want) {
+		t.Fatalf("Names() = %q, se esperaba %q", names, want)
+	}
+
+	tests := []struct {
+		name       string
+		wantParams []string
+	}{
+		{name: "mbr", wantParams: []string{"-name", "-path", "-id"}},
+		{name: "ls", wantParams: []string{"-name", "-path", "-id", "-path_file_ls"}},
+		{name: "file", wantParams: []string{"-name", "-path", "-id", "-path_file_ls"}},
+	}
+	for _, tt := range tests {
+		report, ok := Lookup(tt.name)
+		if !ok {
+			t.Fatalf("Lookup(%q) no encontró el reporte", tt.name)
+		}
+		if params := RequiredParams(report); !slices.Equal(params, tt.wantParams) {
+			t.Errorf("RequiredParams(%q) = %q, se esperaba %q", tt.name, params, tt.wantParams)
+		}
+	}
+	if _, ok := Lookup("noexiste"); ok {
+		t.Fatal("Lookup() encontró un reporte que no está registrado")
+	}
+
+	// Registrar dos veces el mismo nombre es un error de programación
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register() aceptó un nombre repetido")
+		}
+	}()
+	Register(&reportFunc{name: "mbr"})
+}
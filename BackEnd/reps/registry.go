@@ -0,0 +1,96 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"fmt"
+	"sort"
+)
+
+// Requirements indica lo que un reporte necesita para poder generarse
+type Requirements struct {
+	MountedPartition bool // Necesita la partición montada indicada en -id
+	Filesystem       bool // Necesita que la partición montada esté formateada
+	FilePath         bool // Necesita la ruta de un archivo o carpeta en -path_file_ls
+	Login            bool // Necesita una sesión activa
+}
+
+// ReportContext contiene los datos con los que se genera un reporte
+type ReportContext struct {
+	MBR        *structs.MBR        // MBR del disco de la partición montada
+	Superblock *structs.Superblock // Superbloque de la partición montada, nil si el reporte no requiere Filesystem
+	DiskPath   string              // Ruta del disco de la partición montada
+	Path       string              // Ruta del archivo de salida (-path)
+	FilePath   string              // Ruta dentro del sistema de archivos (-path_file_ls)
+}
+
+// Report define un reporte que puede generarse con el comando rep
+type Report interface {
+	Name() string                      // Nombre usado en -name
+	Description() string               // Descripción mostrada en help rep
+	Requirements() Requirements        // Lo que el reporte necesita para generarse
+	Generate(ctx *ReportContext) error // Genera el reporte en ctx.Path
+}
+
+// registry almacena los reportes registrados por nombre
+var registry = make(map[string]Report)
+
+// Register agrega un reporte al registro, cada reporte se registra desde el init de su archivo
+func Register(report Report) {
+	if _, exists := registry[report.Name()]; exists {
+		panic(fmt.Sprintf("el reporte '%s' ya está registrado", report.Name()))
+	}
+	registry[report.Name()] = report
+}
+
+// Lookup busca un reporte registrado por su nombre
+func Lookup(name string) (Report, bool) {
+	report, ok := registry[name]
+	return report, ok
+}
+
+// Reports devuelve los reportes registrados ordenados por nombre
+func Reports() []Report {
+	reports := make([]Report, 0, len(registry))
+	for _, report := range registry {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Name() < reports[j].Name()
+	})
+	return reports
+}
+
+// Names devuelve los nombres de los reportes registrados ordenados
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for _, report := range Reports() {
+		names = append(names, report.Name())
+	}
+	return names
+}
+
+// RequiredParams devuelve los parámetros de rep que necesita el reporte
+func RequiredParams(report Report) []string {
+	params := []string{"-name", "-path"}
+	requirements := report.Requirements()
+	if requirements.MountedPartition {
+		params = append(params, "-id")
+	}
+	if requirements.FilePath {
+		params = append(params, "-path_file_ls")
+	}
+	return params
+}
+
+// reportFunc implementa Report a partir de una función, la usan los reportes del paquete al registrarse
+type reportFunc struct {
+	name         string
+	description  string
+	requirements Requirements
+	generate     func(ctx *ReportContext) error
+}
+
+func (r *reportFunc) Name() string                      { return r.name }
+func (r *reportFunc) Description() string               { return r.description }
+func (r *reportFunc) Requirements() Requirements        { return r.requirements }
+func (r *reportFunc) Generate(ctx *ReportContext) error { return r.generate(ctx) }
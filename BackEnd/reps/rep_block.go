@@ -6,10 +6,20 @@ import (
 	"fmt"
 	"html"
 	"os"
-	"os/exec"
 	"strings"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "block",
+		description:  "Bloques en uso de cada inodo y sus conexiones",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportBlock(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
 // ReportBlockConnections genera un reporte visual de bloques usando Graphviz
 func ReportBlock(superblock *structs.Superblock, diskPath string, path string) error {
 	// Crear las carpetas padre si no existen
@@ -25,14 +35,11 @@ func ReportBlock(superblock *structs.Superblock, diskPath string, path string) e
 	}
 	defer file.Close()
 
-	// Obtener el nombre base del archivo sin la extensión
-	dotFileName, outputImage := utils.GetFileNames(path)
-
 	// Inicio del Dot
 	dotContent := initDotGraph()
 
 	// Generar los bloques y sus conexiones
-	dotContent, connections, err := generateBlockGraph(dotContent, superblock, file)
+	dotContent, connections, blocks, err := generateBlockGraph(dotContent, superblock, file)
 	if err != nil {
 		return err
 	}
@@ -40,68 +47,56 @@ func ReportBlock(superblock *structs.Superblock, diskPath string, path string) e
 	dotContent += connections // Agregar conexiones fuera de las definiciones de nodos
 	dotContent += "}"         // Fin del Dot
 
-	// Crear el archivo DOT
-	err = writeDotFile(dotFileName, dotContent)
-	if err != nil {
-		return err
-	}
-
-	// Ejecutar Graphviz para generar la imagen
-	err = GenerateblockImage(dotFileName, outputImage)
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{dot: dotContent, data: blocks})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Imagen de los bloques generada:", outputImage)
-	return nil
-}
-
-// GenerateblockImage genera una imagen a partir del archivo DOT usando Graphviz
-func GenerateblockImage(dotFileName string, outputImage string) error {
-	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr // Esto imprimirá los errores en la consola
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("error al ejecutar Graphviz: %v", err)
-	}
-
+	fmt.Println("Reporte de los bloques generado:", path)
 	return nil
 }
 
 // generateBlockGraph genera el contenido del grafo de bloques en formato DOT
-func generateBlockGraph(dotContent string, superblock *structs.Superblock, file *os.File) (string, string, error) {
+func generateBlockGraph(dotContent string, superblock *structs.Superblock, file *os.File) (string, string, []blockJSON, error) {
 	visitedBlocks := make(map[int32]bool)
 	var connections string
+	blocks := []blockJSON{}
 
 	for i := int32(0); i < superblock.S_inodes_count; i++ {
-		inode := &structs.Inode{}
-		err := inode.Decode(file, int64(superblock.S_inode_start+(i*superblock.S_inode_size)))
+		// Solo los inodos en uso tienen bloques asignados
+		used, err := superblock.IsInodeUsed(file, i)
 		if err != nil {
-			return "", "", fmt.Errorf("error al deserializar el inodo %d: %v", i, err)
+			return "", "", nil, err
 		}
-
-		if inode.I_uid == -1 || inode.I_uid == 0 {
+		if !used {
 			continue
 		}
 
+		inode := &structs.Inode{}
+		err = inode.Decode(file, int64(superblock.S_inode_start+(i*superblock.S_inode_size)))
+		if err != nil {
+			return "", "", nil, fmt.Errorf("error al deserializar el inodo %d: %v", i, err)
+		}
+
 		// Recorrer los bloques asociados al inodo
 		for _, block := range inode.I_block {
 			if block != -1 {
 				if !visitedBlocks[block] {
-					dotContent, connections, err = generateBlockLabel(dotContent, connections, block, inode, superblock, file, visitedBlocks)
+					dotContent, connections, err = generateBlockLabel(dotContent, connections, block, inode, superblock, file, &blocks)
 					if err != nil {
-						return "", "", err
+						return "", "", nil, err
 					}
 					visitedBlocks[block] = true
 				}
 			}
 		}
 	}
-	return dotContent, connections, nil
+	return dotContent, connections, blocks, nil
 }
 
-func generateBlockLabel(dotContent, connections string, blockIndex int32, inode *structs.Inode, superblock *structs.Superblock, file *os.File, visitedBlocks map[int32]bool) (string, string, error) {
+// generateBlockLabel agrega el bloque al DOT y a la lista de bloques del reporte JSON
+func generateBlockLabel(dotContent, connections string, blockIndex int32, inode *structs.Inode, superblock *structs.Superblock, file *os.File, blocks *[]blockJSON) (string, string, error) {
 	blockOffset := int64(superblock.S_block_start + (blockIndex * superblock.S_block_size))
 
 	if inode.I_type[0] == '0' { // Bloque de carpeta
@@ -110,6 +105,7 @@ func generateBlockLabel(dotContent, connections string, blockIndex int32, inode
 		if err != nil {
 			return "", "", fmt.Errorf("error al decodificar bloque de carpeta %d: %w", blockIndex, err)
 		}
+		*blocks = append(*blocks, newFolderBlockJSON(blockIndex, folderBlock))
 
 		// Generar la etiqueta del bloque de carpeta con bordes
 		label := fmt.Sprintf("BLOQUE DE CARPETA %d", blockIndex)
@@ -143,11 +139,12 @@ func generateBlockLabel(dotContent, connections string, blockIndex int32, inode
 		}
 
 	} else if inode.I_type[0] == '1' { // Bloque de archivo
-		fileBlock := &structs.FileBlock{}
+		fileBlock := superblock.NewFileBlock()
 		err := fileBlock.Decode(file, blockOffset)
 		if err != nil {
 			return "", "", fmt.Errorf("error al decodificar bloque de archivo %d: %w", blockIndex, err)
 		}
+		*blocks = append(*blocks, newFileBlockJSON(blockIndex, fileBlock))
 
 		content := cleanBlockContent(fileBlock.GetContent())
 
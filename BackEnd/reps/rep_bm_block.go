@@ -1,7 +1,6 @@
 package reps
 
 import (
-	"encoding/binary"
 	"fmt"
 	"os"
 	"strings"
@@ -10,6 +9,17 @@ import (
 	utils "backend/utils"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "bm_block",
+		description:  "Bitmap de bloques, 20 por línea",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportBMBlock(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
 // ReportBMBlock genera un reporte del bitmap de bloques y lo guarda en la ruta especificada
 func ReportBMBlock(superblock *structures.Superblock, diskPath string, outputPath string) error {
 	// Crear las carpetas padre si no existen
@@ -26,26 +36,22 @@ func ReportBMBlock(superblock *structures.Superblock, diskPath string, outputPat
 	defer file.Close()
 
 	// Calcular el número total de bloques
-	totalBlocks := superblock.S_blocks_count + superblock.S_free_blocks_count
+	totalBlocks := superblock.S_blocks_count
 	// Calcular cuántos bytes necesita el bitmap (cada byte tiene 8 bits)
 	byteCount := (totalBlocks + 7) / 8
 
 	// Variable para almacenar el contenido del reporte del bitmap de bloques
 	var bitmapContent strings.Builder
 
-	for byteIndex := int32(0); byteIndex < byteCount; byteIndex++ {
-		// Mover el puntero al byte correspondiente en el bitmap de bloques
-		_, err := file.Seek(int64(superblock.S_bm_block_start+byteIndex), 0)
-		if err != nil {
-			return fmt.Errorf("error al posicionar el archivo: %v", err)
-		}
+	// Leer el bitmap completo de una vez
+	bitmap := make([]byte, byteCount)
+	err = utils.ReadBytes(file, int64(superblock.S_bm_block_start), bitmap)
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap: %v", err)
+	}
 
-		// Leer un byte del bitmap
-		var byteVal byte
-		err = binary.Read(file, binary.LittleEndian, &byteVal)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del bitmap: %v", err)
-		}
+	for byteIndex := int32(0); byteIndex < byteCount; byteIndex++ {
+		byteVal := bitmap[byteIndex]
 
 		// Procesar cada bit del byte (cada bit representa un bloque)
 		for bitOffset := 0; bitOffset < 8; bitOffset++ {
@@ -68,16 +74,16 @@ func ReportBMBlock(superblock *structures.Superblock, diskPath string, outputPat
 		}
 	}
 
-	// Guardar el reporte en el archivo especificado
-	txtFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("error al crear el archivo de reporte: %v", err)
-	}
-	defer txtFile.Close()
-
-	_, err = txtFile.WriteString(bitmapContent.String())
+	// Generar el reporte en el formato indicado por la extensión
+	content := bitmapContent.String()
+	err = writeReport(outputPath, reportOutput{
+		dot:    textDot("BITMAP DE BLOQUES", content),
+		text:   content,
+		tables: []*svgTable{bitmapSvgTable("BITMAP DE BLOQUES", content)},
+		data:   newBitmapJSON(content, totalBlocks),
+	})
 	if err != nil {
-		return fmt.Errorf("error al escribir en el archivo de reporte: %v", err)
+		return err
 	}
 
 	fmt.Println("Reporte del bitmap de bloques generado correctamente:", outputPath)
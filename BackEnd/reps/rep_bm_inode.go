@@ -1,7 +1,6 @@
 package reps
 
 import (
-	"encoding/binary"
 	"fmt"
 	"os"
 	"strings"
@@ -10,6 +9,17 @@ import (
 	utils "backend/utils"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "bm_inode",
+		description:  "Bitmap de inodos, 20 por línea",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportBMInode(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
 // ReportBMInode genera un reporte del bitmap de inodos y lo guarda en la ruta especificada
 func ReportBMInode(superblock *structures.Superblock, diskPath string, outputPath string) error {
 	// Crear las carpetas padre si no existen
@@ -26,26 +36,22 @@ func ReportBMInode(superblock *structures.Superblock, diskPath string, outputPat
 	defer file.Close()
 
 	// Calcular el número total de inodos
-	totalInodes := superblock.S_inodes_count + superblock.S_free_inodes_count
+	totalInodes := superblock.S_inodes_count
 	// Calcular cuántos bytes necesita el bitmap (cada byte tiene 8 bits)
 	byteCount := (totalInodes + 7) / 8
 
 	// Variable para almacenar el contenido del reporte del bitmap de inodos
 	var bitmapContent strings.Builder
 
-	for byteIndex := int32(0); byteIndex < byteCount; byteIndex++ {
-		// Mover el puntero al byte correspondiente en el bitmap de inodos
-		_, err := file.Seek(int64(superblock.S_bm_inode_start+byteIndex), 0)
-		if err != nil {
-			return fmt.Errorf("error al posicionar el archivo: %v", err)
-		}
+	// Leer el bitmap completo de una vez
+	bitmap := make([]byte, byteCount)
+	err = utils.ReadBytes(file, int64(superblock.S_bm_inode_start), bitmap)
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap: %v", err)
+	}
 
-		// Leer un byte del bitmap
-		var byteVal byte
-		err = binary.Read(file, binary.LittleEndian, &byteVal)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del bitmap: %v", err)
-		}
+	for byteIndex := int32(0); byteIndex < byteCount; byteIndex++ {
+		byteVal := bitmap[byteIndex]
 
 		// Procesar cada bit del byte (cada bit representa un inodo)
 		for bitOffset := 0; bitOffset < 8; bitOffset++ {
@@ -68,18 +74,42 @@ func ReportBMInode(superblock *structures.Superblock, diskPath string, outputPat
 		}
 	}
 
-	// Guardar el reporte en el archivo especificado
-	txtFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("error al crear el archivo de reporte: %v", err)
-	}
-	defer txtFile.Close()
-
-	_, err = txtFile.WriteString(bitmapContent.String())
+	// Generar el reporte en el formato indicado por la extensión
+	content := bitmapContent.String()
+	err = writeReport(outputPath, reportOutput{
+		dot:    textDot("BITMAP DE INODOS", content),
+		text:   content,
+		tables: []*svgTable{bitmapSvgTable("BITMAP DE INODOS", content)},
+		data:   newBitmapJSON(content, totalInodes),
+	})
 	if err != nil {
-		return fmt.Errorf("error al escribir en el archivo de reporte: %v", err)
+		return err
 	}
 
 	fmt.Println("Reporte del bitmap de inodos generado correctamente:", outputPath)
 	return nil
 }
+
+// bitmapJSON representa un bitmap en el reporte JSON
+type bitmapJSON struct {
+	Total  int32  `json:"total"`
+	Used   int32  `json:"used"`
+	Free   int32  `json:"free"`
+	Bitmap string `json:"bitmap"`
+}
+
+// newBitmapJSON convierte el contenido del reporte de bitmap a su representación JSON
+func newBitmapJSON(content string, total int32) bitmapJSON {
+	bits := strings.ReplaceAll(content, "\n", "")
+	used := int32(strings.Count(bits, "1"))
+	return bitmapJSON{Total: total, Used: used, Free: total - used, Bitmap: bits}
+}
+
+// bitmapSvgTable genera la tabla del bitmap para el renderizador SVG, una fila por línea del reporte
+func bitmapSvgTable(title string, content string) *svgTable {
+	table := newSvgTable(title, "#4CAF50", 1)
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		table.addRow(svgCell{text: line, bg: "#FFFDE7"})
+	}
+	return table
+}
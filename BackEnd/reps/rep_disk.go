@@ -3,12 +3,24 @@ package reps
 import (
 	structs "backend/Structs"
 	"backend/utils"
+	"encoding/binary"
 	"fmt"
 	"os"
-	"os/exec"
+	"sort"
 	"strings"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "disk",
+		description:  "Estructura del disco con particiones, EBR, espacios libres y su porcentaje del disco",
+		requirements: Requirements{MountedPartition: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportDisk(ctx.MBR, ctx.Path, ctx.DiskPath)
+		},
+	})
+}
+
 // ReportDisk genera un reporte de la estructura del disco y lo guarda en la ruta especificada
 func ReportDisk(mbr *structs.MBR, path string, diskPath string) error {
 	// Crear las carpetas padre si no existen
@@ -24,113 +36,193 @@ func ReportDisk(mbr *structs.MBR, path string, diskPath string) error {
 	}
 	defer file.Close()
 
-	// Obtener el nombre base del archivo sin la extensión
-	dotFileName, outputImage := utils.GetFileNames(path)
+	// Recorrer el disco en orden, incluyendo la cadena de EBR y los espacios libres
+	data, err := buildDiskLayout(mbr, file)
+	if err != nil {
+		return err
+	}
 
-	dotContent := `digraph G {
-		fontname="Helvetica,Arial,sans-serif"
-		node [fontname="Helvetica,Arial,sans-serif"]
-		edge [fontname="Helvetica,Arial,sans-serif"]
-		concentrate=True;
-		rankdir=TB;
-		node [shape=record];
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{
+		dot:    initDotGraphForDisk(data),
+		data:   data,
+		tables: []*svgTable{diskSvgTable(data)},
+	})
+	if err != nil {
+		return err
+	}
 
-		title [label="Reporte DISK" shape=plaintext fontname="Helvetica,Arial,sans-serif"];
+	fmt.Println("Reporte de disco generado:", path)
+	return nil
+}
 
-		dsk [label="`
+// diskJSON representa la estructura del disco en el reporte JSON
+type diskJSON struct {
+	Size     int32             `json:"size"`
+	Segments []diskSegmentJSON `json:"segments"`
+}
+
+// diskSegmentJSON representa una sección del disco (MBR, EBR, partición o espacio libre)
+type diskSegmentJSON struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name,omitempty"`
+	Start      int32             `json:"start"`
+	Size       int32             `json:"size"`
+	Percentage float64           `json:"percentage"`
+	Segments   []diskSegmentJSON `json:"segments,omitempty"`
+}
 
-	// Calcular el tamaño total del disco y el tamaño usado
+// buildDiskLayout genera las secciones del disco ordenadas por su byte de inicio
+func buildDiskLayout(mbr *structs.MBR, file *os.File) (diskJSON, error) {
 	totalSize := mbr.MbrSize
-	usedSize := int32(0)
+	newSegment := func(kind string, name string, start int32, size int32) diskSegmentJSON {
+		return diskSegmentJSON{
+			Type:       kind,
+			Name:       name,
+			Start:      start,
+			Size:       size,
+			Percentage: (float64(size) / float64(totalSize)) * 100,
+		}
+	}
 
-	// Agregar MBR al reporte
-	dotContent += "{MBR}"
+	data := diskJSON{Size: totalSize}
+	mbrSize := int32(binary.Size(structs.MBR{}))
+	data.Segments = append(data.Segments, newSegment("MBR", "", 0, mbrSize))
 
-	// Recorrer las particiones del MBR y generar el contenido DOT
+	// Ordenar las particiones por su posición en el disco
+	var partitions []structs.Partition
 	for _, part := range mbr.MbrPartitions {
-		if part.Part_size > 0 {
-			// Calcular el porcentaje de uso
-			percentage := (float64(part.Part_size) / float64(totalSize)) * 100
-			usedSize += part.Part_size
-
-			// Convertir Part_name a string y eliminar los caracteres nulos
-			partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-			if part.Part_type[0] == 'P' {
-				// Partición primaria
-				dotContent += fmt.Sprintf("|{Primaria %s\\n%.2f%%}", partName, percentage)
-			} else if part.Part_type[0] == 'E' {
-				// Partición extendida
-				dotContent += fmt.Sprintf("|{Extendida %.2f%%|{", percentage)
-				ebrStart := part.Part_start
-				ebrCount := 0
-				ebrUsedSize := int32(0)
-
-				for ebrStart != -1 {
-					ebr, err := structs.ReadEBR(int32(ebrStart), file)
-					if err != nil {
-						return fmt.Errorf("error al leer EBR: %v", err)
-					}
-
-					ebrName := strings.TrimRight(string(ebr.Ebr_name[:]), "\x00")
-					ebrPercentage := (float64(ebr.Ebr_size) / float64(totalSize)) * 100
-					ebrUsedSize += ebr.Ebr_size
-
-					// Agregar EBR y partición lógica al reporte
-					if ebrCount > 0 {
-						dotContent += "|"
-					}
-					dotContent += fmt.Sprintf("{EBR|Lógica %s\\n%.2f%%}", ebrName, ebrPercentage)
-
-					// Actualizar el inicio para el próximo EBR
-					ebrStart = ebr.Ebr_next
-					ebrCount++
-				}
-
-				// Calcular espacio libre dentro de la partición extendida
-				extendedFreeSize := part.Part_size - ebrUsedSize
-				if extendedFreeSize > 0 {
-					extendedFreePercentage := (float64(extendedFreeSize) / float64(totalSize)) * 100
-					dotContent += fmt.Sprintf("|Libre %.2f%%", extendedFreePercentage)
-				}
-
-				dotContent += "}}"
+		if part.Part_size > 0 && part.Part_start > 0 {
+			partitions = append(partitions, part)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].Part_start < partitions[j].Part_start
+	})
+
+	// Espacio libre entre el final de la sección anterior y el inicio de la siguiente
+	cursor := mbrSize
+	for _, part := range partitions {
+		if part.Part_start > cursor {
+			data.Segments = append(data.Segments, newSegment("Libre", "", cursor, part.Part_start-cursor))
+		}
+
+		// Convertir Part_name a string y eliminar los caracteres nulos
+		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
+		if part.Part_type[0] != 'E' {
+			data.Segments = append(data.Segments, newSegment("Primaria", partName, part.Part_start, part.Part_size))
+			cursor = part.Part_start + part.Part_size
+			continue
+		}
+
+		// Partición extendida: recorrer la cadena de EBR
+		extended := newSegment("Extendida", partName, part.Part_start, part.Part_size)
+		chain, err := structs.ReadEBRChain(part.Part_start, file)
+		if err != nil {
+			return diskJSON{}, fmt.Errorf("error al leer la cadena de EBR: %v", err)
+		}
+
+		ebrSize := int32(binary.Size(structs.EBR{}))
+		extendedEnd := part.Part_start + part.Part_size
+		extendedCursor := part.Part_start
+		for _, ebr := range chain {
+			if ebr.Ebr_start > extendedCursor {
+				extended.Segments = append(extended.Segments, newSegment("Libre", "", extendedCursor, ebr.Ebr_start-extendedCursor))
+			}
+			extended.Segments = append(extended.Segments, newSegment("EBR", "", ebr.Ebr_start, ebrSize))
+			extendedCursor = ebr.Ebr_start + ebrSize
+
+			// El tamaño del EBR incluye la partición lógica que le sigue, 0 indica un EBR vacío
+			if ebr.Ebr_size > ebrSize {
+				ebrName := strings.TrimRight(string(ebr.Ebr_name[:]), "\x00")
+				extended.Segments = append(extended.Segments, newSegment("Lógica", ebrName, extendedCursor, ebr.Ebr_size-ebrSize))
+				extendedCursor = ebr.Ebr_start + ebr.Ebr_size
 			}
 		}
+		if extendedEnd > extendedCursor {
+			extended.Segments = append(extended.Segments, newSegment("Libre", "", extendedCursor, extendedEnd-extendedCursor))
+		}
+
+		data.Segments = append(data.Segments, extended)
+		cursor = extendedEnd
+	}
+
+	// Espacio libre restante al final del disco
+	if totalSize > cursor {
+		data.Segments = append(data.Segments, newSegment("Libre", "", cursor, totalSize-cursor))
 	}
+	return data, nil
+}
+
+// initDotGraphForDisk genera el contenido DOT con un registro por cada sección del disco
+func initDotGraphForDisk(data diskJSON) string {
+	sections := make([]string, 0, len(data.Segments))
+	for _, segment := range data.Segments {
+		if len(segment.Segments) == 0 {
+			sections = append(sections, "{"+diskSegmentRecord(segment)+"}")
+			continue
+		}
 
-	// Calcular espacio libre restante y añadirlo si es necesario
-	freeSize := totalSize - usedSize
-	if freeSize > 0 {
-		freePercentage := (float64(freeSize) / float64(totalSize)) * 100
-		dotContent += fmt.Sprintf("|Libre %.2f%%", freePercentage)
+		children := make([]string, 0, len(segment.Segments))
+		for _, child := range segment.Segments {
+			children = append(children, diskSegmentRecord(child))
+		}
+		sections = append(sections, fmt.Sprintf("{%s|{%s}}", diskSegmentRecord(segment), strings.Join(children, "|")))
 	}
 
-	// Cerrar el nodo de disco y completar el DOT
-	dotContent += `"];
+	return fmt.Sprintf(`digraph G {
+		fontname="Helvetica,Arial,sans-serif"
+		node [fontname="Helvetica,Arial,sans-serif"]
+		edge [fontname="Helvetica,Arial,sans-serif"]
+		concentrate=True;
+		rankdir=TB;
+		node [shape=record];
+
+		title [label="Reporte DISK" shape=plaintext fontname="Helvetica,Arial,sans-serif"];
+
+		dsk [label="%s"];
 
 		title -> dsk [style=invis];
-	}`
+	}`, strings.Join(sections, "|"))
+}
 
-	// Crear el archivo DOT
-	dotFile, err := os.Create(dotFileName)
-	if err != nil {
-		return fmt.Errorf("error al crear el archivo DOT: %v", err)
-	}
-	defer dotFile.Close()
+// diskSegmentRecord genera el texto de una sección para un nodo record de Graphviz
+func diskSegmentRecord(segment diskSegmentJSON) string {
+	label := diskSegmentLabel(segment)
+	// Escapar los caracteres especiales de los nodos record
+	replacer := strings.NewReplacer("{", "\\{", "}", "\\}", "|", "\\|", "<", "\\<", ">", "\\>", "\"", "\\\"")
+	return strings.Replace(replacer.Replace(label), " ", "\\n", 1)
+}
 
-	// Escribir el contenido DOT en el archivo
-	_, err = dotFile.WriteString(dotContent)
-	if err != nil {
-		return fmt.Errorf("error al escribir en el archivo DOT: %v", err)
+// diskSvgTable genera la tabla del disco para el renderizador SVG
+// La primera fila contiene las secciones del disco y la segunda el contenido de la partición extendida
+func diskSvgTable(data diskJSON) *svgTable {
+	columns := 0
+	for _, segment := range data.Segments {
+		columns += max(len(segment.Segments), 1)
 	}
 
-	// Generar la imagen con Graphviz
-	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("error al ejecutar Graphviz: %v", err)
+	colors := map[string]string{"MBR": "#F8D7DA", "Primaria": "#FFDDC1", "Extendida": "#C1E1C1", "EBR": "#FFD1DC", "Lógica": "#C1D1FF", "Libre": "#FFFFFF"}
+	table := newSvgTable("Reporte DISK", "#4CAF50", columns)
+	var sections, extended []svgCell
+	for _, segment := range data.Segments {
+		sections = append(sections, svgCell{text: diskSegmentLabel(segment), bg: colors[segment.Type], colspan: max(len(segment.Segments), 1)})
+		if len(segment.Segments) == 0 {
+			extended = append(extended, svgCell{})
+		}
+		for _, child := range segment.Segments {
+			extended = append(extended, svgCell{text: diskSegmentLabel(child), bg: colors[child.Type]})
+		}
 	}
+	table.addRow(sections...)
+	table.addRow(extended...)
+	return table
+}
 
-	fmt.Println("Reporte de disco generado:", outputImage)
-	return nil
+// diskSegmentLabel genera el texto de una sección del disco con su porcentaje
+func diskSegmentLabel(segment diskSegmentJSON) string {
+	if segment.Type == "MBR" || segment.Type == "EBR" {
+		return segment.Type
+	}
+	return strings.Join(strings.Fields(fmt.Sprintf("%s %s %.2f%%", segment.Type, segment.Name, segment.Percentage)), " ")
 }
@@ -6,8 +6,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "file",
+		description:  "Nombre y contenido del archivo indicado en -path_file_ls",
+		requirements: Requirements{MountedPartition: true, Filesystem: true, FilePath: true, Login: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportFile(ctx.Superblock, ctx.DiskPath, ctx.Path, ctx.FilePath)
+		},
+	})
+}
+
 // ReportFile genera un reporte que contiene el nombre y el contenido de un archivo específico
 func ReportFile(superblock *structs.Superblock, diskPath string, path string, filePath string) error {
 	// Crear las carpetas padre si no existen
@@ -24,7 +36,7 @@ func ReportFile(superblock *structs.Superblock, diskPath string, path string, fi
 	defer file.Close()
 
 	// Buscar el inodo del archivo especificado en filePath
-	inodeIndex, err := findFileInode(superblock, file, filePath)
+	inodeIndex, err := superblock.ResolvePath(file, filePath)
 	if err != nil {
 		return fmt.Errorf("error al buscar el inodo del archivo: %v", err)
 	}
@@ -34,64 +46,29 @@ func ReportFile(superblock *structs.Superblock, diskPath string, path string, fi
 	if err != nil {
 		return fmt.Errorf("error al leer el contenido del archivo: %v", err)
 	}
+	fileContent = strings.TrimRight(fileContent, "\x00") // Quitar el relleno del último bloque
 
-	// Crear el archivo de salida para el reporte
-	reportFile, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("error al crear el archivo de reporte: %v", err)
-	}
-	defer reportFile.Close()
-
-	// Escribir el nombre y el contenido del archivo en el archivo de reporte
+	// Escribir el nombre y el contenido del archivo en el formato indicado por la extensión
 	_, fileName := filepath.Split(filePath)
 	reportContent := fmt.Sprintf("Nombre del archivo: %s\n\nContenido del archivo:\n%s", fileName, fileContent)
-
-	_, err = reportFile.WriteString(reportContent)
+	err = writeReport(path, reportOutput{
+		dot:  textDot(fileName, reportContent),
+		text: reportContent,
+		data: fileJSON{Name: fileName, Path: filePath, Content: fileContent},
+	})
 	if err != nil {
-		return fmt.Errorf("error al escribir en el archivo de reporte: %v", err)
+		return err
 	}
 
 	fmt.Println("Reporte del archivo generado:", path)
 	return nil
 }
 
-// findFileInode busca el inodo del archivo especificado a través de su ruta
-func findFileInode(superblock *structs.Superblock, diskFile *os.File, filePath string) (int32, error) {
-	// Asumimos que partimos del inodo raíz
-	currentInodeIndex := int32(0) // Inodo raíz
-
-	// Dividimos el path en directorios y nombre de archivo
-	directories, fileName := utils.GetParentDirectories(filePath)
-
-	// Navegar por cada directorio para encontrar el inodo final
-	for _, dir := range directories {
-		inode, err := readInode(superblock, diskFile, currentInodeIndex)
-		if err != nil {
-			return -1, fmt.Errorf("error al leer el inodo: %v", err)
-		}
-
-		// Buscar el directorio en el bloque del inodo actual
-		found, nextInodeIndex := findInodeInDirectory(inode, diskFile, dir, superblock)
-		if !found {
-			return -1, fmt.Errorf("directorio '%s' no encontrado", dir)
-		}
-
-		currentInodeIndex = nextInodeIndex
-	}
-
-	// Ahora buscar el archivo en el último directorio
-	inode, err := readInode(superblock, diskFile, currentInodeIndex)
-	if err != nil {
-		return -1, fmt.Errorf("error al leer el inodo del directorio final: %v", err)
-	}
-
-	// Buscar el archivo en el bloque del inodo actual
-	found, fileInodeIndex := findInodeInDirectory(inode, diskFile, fileName, superblock)
-	if !found {
-		return -1, fmt.Errorf("archivo '%s' no encontrado", fileName)
-	}
-
-	return fileInodeIndex, nil
+// fileJSON representa el archivo en el reporte JSON
+type fileJSON struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
 }
 
 // readFileContent lee el contenido de un archivo dado su inodo
@@ -101,23 +78,25 @@ func readFileContent(superblock *structs.Superblock, diskFile *os.File, inodeInd
 		return "", fmt.Errorf("error al leer el inodo del archivo: %v", err)
 	}
 
-	// Concatenar el contenido de los bloques
-	var content string
-	for _, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
+	// Obtener los bloques de datos en orden, incluidos los de los bloques indirectos
+	blocks, err := superblock.FileBlocks(diskFile, inode)
+	if err != nil {
+		return "", fmt.Errorf("error al leer los bloques del archivo: %v", err)
+	}
 
+	// Concatenar el contenido de los bloques
+	var content strings.Builder
+	for _, blockIndex := range blocks {
 		// Leer el bloque de archivo
 		block, err := readFileBlock(superblock, diskFile, blockIndex)
 		if err != nil {
 			return "", fmt.Errorf("error al leer el bloque de archivo: %v", err)
 		}
 
-		content += string(block.B_content[:])
+		content.Write(block.B_content)
 	}
 
-	return content, nil
+	return content.String(), nil
 }
 
 // readInode lee el inodo en la posición dada
@@ -133,7 +112,7 @@ func readInode(superblock *structs.Superblock, diskFile *os.File, inodeIndex int
 
 // readFileBlock lee un bloque de archivo en la posición dada
 func readFileBlock(superblock *structs.Superblock, diskFile *os.File, blockIndex int32) (*structs.FileBlock, error) {
-	block := &structs.FileBlock{}
+	block := superblock.NewFileBlock()
 	offset := int64(superblock.S_block_start + blockIndex*superblock.S_block_size)
 	err := block.Decode(diskFile, offset)
 	if err != nil {
@@ -141,28 +120,3 @@ func readFileBlock(superblock *structs.Superblock, diskFile *os.File, blockIndex
 	}
 	return block, nil
 }
-
-// findInodeInDirectory busca un inodo dentro de un bloque de directorio dado
-func findInodeInDirectory(inode *structs.Inode, diskFile *os.File, name string, superblock *structs.Superblock) (bool, int32) {
-	for _, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-
-		// Leer el bloque de carpeta
-		block := &structs.FolderBlock{}
-		offset := int64(superblock.S_block_start + blockIndex*superblock.S_block_size)
-		err := block.Decode(diskFile, offset)
-		if err != nil {
-			continue
-		}
-
-		// Buscar el nombre dentro del bloque de carpeta
-		for _, content := range block.B_content {
-			if string(content.B_name[:]) == name {
-				return true, content.B_inodo
-			}
-		}
-	}
-	return false, -1
-}
@@ -5,10 +5,20 @@ import (
 	"backend/utils"
 	"fmt"
 	"os"
-	"os/exec"
 	"time"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "inode",
+		description:  "Tabla de cada inodo en uso",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportInode(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
 // ReportInode genera un reporte de los inodos y lo guarda en la ruta especificada
 func ReportInode(superblock *structs.Superblock, diskPath string, path string) error {
 	// Crear las carpetas padre si no existen
@@ -24,38 +34,30 @@ func ReportInode(superblock *structs.Superblock, diskPath string, path string) e
 	}
 	defer file.Close()
 
-	// Obtener el nombre base del archivo sin la extensión
-	dotFileName, outputImage := utils.GetFileNames(path)
-
-	// Inicio del Dot
-	dotContent := initDotGraph()
-
 	// Si no hay inodos, devolver un error
-	if superblock.S_inodes_count == 0 {
+	if superblock.UsedInodes() == 0 {
 		return fmt.Errorf("no hay inodos en el sistema")
 	}
 
 	// Generar los inodos y sus conexiones
-	dotContent, err = generateInodeGraph(dotContent, superblock, file)
+	dotContent, inodes, err := generateInodeGraph(initDotGraph(), superblock, file)
 	if err != nil {
 		return err
 	}
 
 	dotContent += "}" // Fin del Dot
 
-	// Crear el archivo DOT
-	err = writeDotFile(dotFileName, dotContent)
-	if err != nil {
-		return err
+	// Generar el reporte en el formato indicado por la extensión
+	tables := make([]*svgTable, 0, len(inodes))
+	for _, inode := range inodes {
+		tables = append(tables, inodeSvgTable(inode))
 	}
-
-	// Ejecutar Graphviz para generar la imagen
-	err = generateInodeImage(dotFileName, outputImage)
+	err = writeReport(path, reportOutput{dot: dotContent, data: inodes, tables: tables})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Imagen de los inodos generada:", outputImage)
+	fmt.Println("Reporte de los inodos generado:", path)
 	return nil
 }
 
@@ -77,28 +79,62 @@ func initDotGraph() string {
 }
 
 // generateInodeGraph genera el contenido del grafo de inodos en formato DOT
-func generateInodeGraph(dotContent string, superblock *structs.Superblock, file *os.File) (string, error) {
+func generateInodeGraph(dotContent string, superblock *structs.Superblock, file *os.File) (string, []inodeJSON, error) {
+	var inodes []inodeJSON
+	previous := int32(-1)
 	for i := int32(0); i < superblock.S_inodes_count; i++ {
-		inode := &structs.Inode{}
-		err := inode.Decode(file, int64(superblock.S_inode_start+(i*superblock.S_inode_size)))
+		// Verificar si el inodo está en uso
+		used, err := superblock.IsInodeUsed(file, i)
 		if err != nil {
-			return "", fmt.Errorf("error al deserializar el inodo %d: %v", i, err)
+			return "", nil, err
 		}
-
-		// Verificar si el inodo está en uso
-		if inode.I_uid == -1 || inode.I_uid == 0 {
+		if !used {
 			continue
 		}
 
+		inode := &structs.Inode{}
+		err = inode.Decode(file, int64(superblock.S_inode_start+(i*superblock.S_inode_size)))
+		if err != nil {
+			return "", nil, fmt.Errorf("error al deserializar el inodo %d: %v", i, err)
+		}
+
 		// Generar la tabla del inodo
 		dotContent += generateInodeTable(i, inode)
+		inodes = append(inodes, newInodeJSON(i, inode))
 
-		// Conexión entre inodos
-		if i < superblock.S_inodes_count-1 {
-			dotContent += fmt.Sprintf("inode%d -> inode%d [color=\"#FF7043\"];\n", i, i+1)
+		// Conexión con el inodo en uso anterior
+		if previous != -1 {
+			dotContent += fmt.Sprintf("inode%d -> inode%d [color=\"#FF7043\"];\n", previous, i)
 		}
+		previous = i
 	}
-	return dotContent, nil
+	return dotContent, inodes, nil
+}
+
+// inodeSvgTable genera la tabla de un inodo para el renderizador SVG
+func inodeSvgTable(inode inodeJSON) *svgTable {
+	table := newSvgTable(fmt.Sprintf("INODO %d", inode.Index), "#4CAF50", 2)
+	bg := "#FFFDE7"
+	table.addField("i_uid", inode.Uid, bg)
+	table.addField("i_gid", inode.Gid, bg)
+	table.addField("i_size", inode.Size, bg)
+	table.addField("i_atime", inode.Atime, bg)
+	table.addField("i_ctime", inode.Ctime, bg)
+	table.addField("i_mtime", inode.Mtime, bg)
+	table.addField("i_type", inode.Type, bg)
+	table.addField("i_perm", inode.Perm, bg)
+
+	// Bloques directos e indirectos en uso
+	titles := map[int]string{0: "BLOQUES DIRECTOS", 12: "BLOQUE INDIRECTO SIMPLE", 13: "BLOQUE INDIRECTO DOBLE", 14: "BLOQUE INDIRECTO TRIPLE"}
+	for i, block := range inode.Blocks {
+		if title, ok := titles[i]; ok && (i == 0 || block != -1) {
+			table.addRow(svgCell{text: title, bold: true, bg: "#FF9800", colspan: 2})
+		}
+		if block != -1 {
+			table.addField(fmt.Sprint(i+1), block, bg)
+		}
+	}
+	return table
 }
 
 // generateInodeTable genera la tabla con los atributos y bloques del inodo en formato DOT
@@ -183,14 +219,3 @@ func writeDotFile(dotFileName string, dotContent string) error {
 
 	return nil
 }
-
-// generateInodeImage genera una imagen a partir del archivo DOT usando Graphviz
-func generateInodeImage(dotFileName string, outputImage string) error {
-	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("error al ejecutar Graphviz: %v", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,124 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"backend/utils"
+	"fmt"
+	"html"
+	"os"
+	"time"
+)
+
+func init() {
+	Register(&reportFunc{
+		name:         "journaling",
+		description:  "Entradas del journal de una partición ext3",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportJournaling(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
+// ReportJournaling genera un reporte en formato de tabla con las entradas del journal de una partición ext3
+func ReportJournaling(superblock *structs.Superblock, diskPath string, path string) error {
+	// El journal solo existe en particiones ext3
+	if superblock.S_filesystem_type != 3 {
+		return fmt.Errorf("el reporte journaling solo está disponible para particiones ext3, la partición es ext%d", superblock.S_filesystem_type)
+	}
+
+	// Crear las carpetas padre si no existen
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return fmt.Errorf("error al crear directorios: %v", err)
+	}
+
+	// Abrir el archivo de disco
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+	}
+	defer file.Close()
+
+	// Leer las entradas del journal
+	entries, err := superblock.ReadJournalEntries(file)
+	if err != nil {
+		return err
+	}
+
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{
+		dot:  initDotGraphForJournaling(entries),
+		data: newJournalJSON(entries),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Reporte del journaling generado:", path)
+	return nil
+}
+
+// journalEntryJSON representa una entrada del journal en el reporte JSON
+type journalEntryJSON struct {
+	Count     int32  `json:"count"`
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Date      string `json:"date"`
+}
+
+// newJournalJSON convierte las entradas del journal a su representación JSON
+func newJournalJSON(entries []structs.Journal) []journalEntryJSON {
+	data := make([]journalEntryJSON, 0, len(entries))
+	for _, entry := range entries {
+		data = append(data, journalEntryJSON{
+			Count:     entry.J_count,
+			Operation: entry.GetOperation(),
+			Path:      entry.GetPath(),
+			Content:   entry.GetContent(),
+			Date:      entry.GetDate().Format(time.RFC3339),
+		})
+	}
+	return data
+}
+
+// initDotGraphForJournaling genera el contenido DOT con una fila por cada entrada del journal
+func initDotGraphForJournaling(entries []structs.Journal) string {
+	dotContent := `digraph G {
+		fontname="Helvetica,Arial,sans-serif"
+		node [fontname="Helvetica,Arial,sans-serif", shape=plain, fontsize=12];
+		bgcolor="#FAFAFA";
+
+		journalTable [label=<
+			<table border="0" cellborder="1" cellspacing="0" cellpadding="6" bgcolor="#FFF9C4">
+				<tr><td colspan="5" bgcolor="#4CAF50" align="center"><b>REPORTE DE JOURNALING</b></td></tr>
+				<tr>
+					<td bgcolor="#FF9800"><b>#</b></td>
+					<td bgcolor="#FF9800"><b>Operación</b></td>
+					<td bgcolor="#FF9800"><b>Path</b></td>
+					<td bgcolor="#FF9800"><b>Contenido</b></td>
+					<td bgcolor="#FF9800"><b>Fecha</b></td>
+				</tr>
+	`
+
+	for _, entry := range entries {
+		dotContent += fmt.Sprintf(`
+				<tr><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			entry.J_count,
+			html.EscapeString(entry.GetOperation()),
+			html.EscapeString(entry.GetPath()),
+			html.EscapeString(entry.GetContent()),
+			entry.GetDate().Format("02/01/2006 15:04"))
+	}
+
+	if len(entries) == 0 {
+		dotContent += `
+				<tr><td colspan="5">El journal no tiene entradas</td></tr>`
+	}
+
+	dotContent += `
+			</table>>];
+	}`
+	return dotContent
+}
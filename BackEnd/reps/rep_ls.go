@@ -0,0 +1,240 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"backend/utils"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(&reportFunc{
+		name:         "ls",
+		description:  "Entradas de la carpeta indicada en -path_file_ls con permisos, dueño y fechas",
+		requirements: Requirements{MountedPartition: true, Filesystem: true, FilePath: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportLs(ctx.Superblock, ctx.DiskPath, ctx.Path, ctx.FilePath)
+		},
+	})
+}
+
+// ReportLs genera un reporte tipo ls con las entradas del directorio indicado en dirPath
+func ReportLs(superblock *structs.Superblock, diskPath string, path string, dirPath string) error {
+	// Crear las carpetas padre si no existen
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return fmt.Errorf("error al crear directorios: %v", err)
+	}
+
+	// Abrir el archivo de disco
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+	}
+	defer file.Close()
+
+	// Buscar el inodo del directorio
+	dirInodeIndex, err := superblock.ResolvePath(file, dirPath)
+	if err != nil {
+		return fmt.Errorf("error al buscar el directorio: %v", err)
+	}
+
+	dirInode, err := readInode(superblock, file, dirInodeIndex)
+	if err != nil {
+		return err
+	}
+	if dirInode.I_type[0] != '0' {
+		return fmt.Errorf("'%s' no es un directorio", dirPath)
+	}
+
+	// Cargar los nombres de usuarios y grupos desde users.txt
+	users, groups, err := loadUsersAndGroups(superblock, file)
+	if err != nil {
+		return err
+	}
+
+	// Generar la tabla con las entradas del directorio
+	dotContent, entries, err := initDotGraphForLs(superblock, file, dirPath, dirInode, users, groups)
+	if err != nil {
+		return err
+	}
+
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{dot: dotContent, data: entries})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Reporte ls generado:", path)
+	return nil
+}
+
+// lsEntryJSON representa una entrada del directorio en el reporte JSON
+type lsEntryJSON struct {
+	Permissions string `json:"permissions"`
+	Owner       string `json:"owner"`
+	Group       string `json:"group"`
+	Size        int32  `json:"size"`
+	Atime       string `json:"atime"`
+	Mtime       string `json:"mtime"`
+	Ctime       string `json:"ctime"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+}
+
+// initDotGraphForLs genera el contenido DOT con una fila por cada entrada del directorio
+func initDotGraphForLs(superblock *structs.Superblock, file *os.File, dirPath string, dirInode *structs.Inode, users, groups map[int32]string) (string, []lsEntryJSON, error) {
+	entries := []lsEntryJSON{}
+	dotContent := fmt.Sprintf(`digraph G {
+		fontname="Helvetica,Arial,sans-serif"
+		node [fontname="Helvetica,Arial,sans-serif", shape=plain, fontsize=12];
+		bgcolor="#FAFAFA";
+
+		lsTable [label=<
+			<table border="0" cellborder="1" cellspacing="0" cellpadding="6" bgcolor="#FFFDE7">
+				<tr><td colspan="9" bgcolor="#4CAF50" align="center"><b>REPORTE LS: %s</b></td></tr>
+				<tr>
+					<td bgcolor="#FF9800"><b>Permisos</b></td>
+					<td bgcolor="#FF9800"><b>Propietario</b></td>
+					<td bgcolor="#FF9800"><b>Grupo</b></td>
+					<td bgcolor="#FF9800"><b>Tamaño</b></td>
+					<td bgcolor="#FF9800"><b>Último Acceso</b></td>
+					<td bgcolor="#FF9800"><b>Última Modificación</b></td>
+					<td bgcolor="#FF9800"><b>Último Cambio</b></td>
+					<td bgcolor="#FF9800"><b>Tipo</b></td>
+					<td bgcolor="#FF9800"><b>Nombre</b></td>
+				</tr>
+	`, html.EscapeString(dirPath))
+
+	// Obtener los bloques de carpeta en orden, I_block[12:] son bloques de apuntadores y no tienen entradas
+	blocks, err := superblock.FileBlocks(file, dirInode)
+	if err != nil {
+		return "", nil, fmt.Errorf("error al leer los bloques de la carpeta: %v", err)
+	}
+
+	for _, blockIndex := range blocks {
+		// Leer el bloque de carpeta
+		block := &structs.FolderBlock{}
+		err := block.Decode(file, int64(superblock.S_block_start+blockIndex*superblock.S_block_size))
+		if err != nil {
+			return "", nil, fmt.Errorf("error al decodificar el bloque de carpeta %d: %v", blockIndex, err)
+		}
+
+		for _, content := range block.B_content {
+			name := cleanBlockName(content.B_name)
+			if content.B_inodo == -1 || name == "." || name == ".." {
+				continue
+			}
+
+			inode, err := readInode(superblock, file, content.B_inodo)
+			if err != nil {
+				return "", nil, err
+			}
+
+			tipo := "Archivo"
+			if inode.I_type[0] == '0' {
+				tipo = "Carpeta"
+			}
+
+			entry := lsEntryJSON{
+				Permissions: formatPermissions(inode),
+				Owner:       lookupName(users, inode.I_uid),
+				Group:       lookupName(groups, inode.I_gid),
+				Size:        inode.I_size,
+				Atime:       formatInodeTime(inode.I_atime),
+				Mtime:       formatInodeTime(inode.I_mtime),
+				Ctime:       formatInodeTime(inode.I_ctime),
+				Type:        tipo,
+				Name:        name,
+			}
+			entries = append(entries, entry)
+
+			dotContent += fmt.Sprintf(`
+				<tr>
+					<td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td>
+				</tr>`,
+				entry.Permissions,
+				html.EscapeString(entry.Owner),
+				html.EscapeString(entry.Group),
+				entry.Size,
+				entry.Atime,
+				entry.Mtime,
+				entry.Ctime,
+				entry.Type,
+				html.EscapeString(entry.Name))
+		}
+	}
+
+	dotContent += `
+			</table>>];
+	}`
+	return dotContent, entries, nil
+}
+
+// loadUsersAndGroups lee users.txt (inodo 1) y devuelve los nombres de usuarios y grupos activos por ID
+func loadUsersAndGroups(superblock *structs.Superblock, file *os.File) (map[int32]string, map[int32]string, error) {
+	content, err := readFileContent(superblock, file, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al leer users.txt: %v", err)
+	}
+
+	users := make(map[int32]string)
+	groups := make(map[int32]string)
+	for _, linea := range strings.Split(strings.TrimRight(content, "\x00"), "\n") {
+		campos := strings.Split(strings.TrimSpace(linea), ",")
+		if len(campos) < 3 {
+			continue // Ignorar líneas mal formadas
+		}
+
+		id, err := strconv.Atoi(campos[0])
+		if err != nil || id == 0 {
+			continue // Ignorar entradas eliminadas o IDs mal formados
+		}
+
+		if campos[1] == "G" {
+			groups[int32(id)] = campos[2]
+		} else if campos[1] == "U" && len(campos) == 5 {
+			users[int32(id)] = campos[3]
+		}
+	}
+	return users, groups, nil
+}
+
+// lookupName devuelve el nombre asociado al ID o el ID mismo si no existe
+func lookupName(names map[int32]string, id int32) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return strconv.Itoa(int(id))
+}
+
+// formatPermissions convierte I_perm (por ejemplo 664) al formato -rw-rw-r--
+func formatPermissions(inode *structs.Inode) string {
+	var builder strings.Builder
+	if inode.I_type[0] == '0' {
+		builder.WriteByte('d')
+	} else {
+		builder.WriteByte('-')
+	}
+
+	for _, digit := range inode.I_perm {
+		value := digit - '0'
+		for i, flag := range "rwx" {
+			if value&(4>>i) != 0 {
+				builder.WriteRune(flag)
+			} else {
+				builder.WriteByte('-')
+			}
+		}
+	}
+	return builder.String()
+}
+
+// formatInodeTime convierte un tiempo del inodo a fecha y hora legibles
+func formatInodeTime(t float32) string {
+	return time.Unix(int64(t), 0).Format("02/01/2006 15:04")
+}
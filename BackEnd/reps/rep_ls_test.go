@@ -0,0 +1,106 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestPartition crea una imagen .mia temporal formateada con el mismo código que mkfs
+// Devuelve el path de la imagen, el archivo abierto y el superbloque
+func newTestPartition(t *testing.T) (string, *os.File, *structs.Superblock) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "disco.mia")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	const partStart = 512
+	sb := structs.NewSuperblock(partStart, 32, structs.DefaultBlockSize, structs.DefaultInodeRatio, 2)
+	err = file.Truncate(sb.End())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sb.Format(file, partStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, file, sb
+}
+
+func TestReportLsIndirectBlocks(t *testing.T) {
+	diskPath, file, sb := newTestPartition(t)
+	if err := sb.CreateFolder(file, "/dir"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.CreateFile(file, "/dir/a.txt", 1, []string{"a"}, structs.FirstFit); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.CreateFile(file, "/c.txt", 1, []string{"c"}, structs.FirstFit); err != nil {
+		t.Fatal(err)
+	}
+	dirIndex, err := sb.ResolvePath(file, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cIndex, err := sb.ResolvePath(file, "/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Agregar a /dir un bloque de carpeta con c.txt al que se llega por el indirecto simple
+	folderIndex, err := sb.AllocateBlock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folder := structs.NewFolderBlock()
+	folder.B_content[0] = structs.FolderContent{B_inodo: cIndex}
+	copy(folder.B_content[0].B_name[:], "c.txt")
+	if err := folder.Encode(file, sb.CalculateBlockOffset(folderIndex)); err != nil {
+		t.Fatal(err)
+	}
+	pointerIndex, err := sb.AllocateBlock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pointers := structs.NewPointerBlock()
+	pointers.B_pointers[0] = folderIndex
+	if err := pointers.Encode(file, sb.CalculateBlockOffset(pointerIndex)); err != nil {
+		t.Fatal(err)
+	}
+	dir := &structs.Inode{}
+	if err := dir.Decode(file, sb.CalculateInodeOffset(dirIndex)); err != nil {
+		t.Fatal(err)
+	}
+	dir.I_block[12] = pointerIndex
+	if err := dir.Encode(file, sb.CalculateInodeOffset(dirIndex)); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "ls.json")
+	if err := ReportLs(sb, diskPath, output, "/dir"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []lsEntryJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// El bloque de apuntadores no se lee como un bloque de carpeta
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "c.txt" {
+		t.Fatalf("entradas de /dir = %q, se esperaba [a.txt c.txt]", names)
+	}
+}
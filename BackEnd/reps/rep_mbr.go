@@ -3,13 +3,30 @@ package reps
 import (
 	structures "backend/Structs"
 	utils "backend/utils"
+	"encoding/binary"
 	"fmt"
+	"html"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "mbr",
+		description:  "Tabla del MBR con sus particiones y los EBR de la partición extendida",
+		requirements: Requirements{MountedPartition: true},
+		generate: func(ctx *ReportContext) error {
+			file, err := os.Open(ctx.DiskPath)
+			if err != nil {
+				return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+			}
+			defer file.Close()
+			return ReportMBR(ctx.MBR, ctx.Path, file)
+		},
+	})
+}
+
 // ReportMBR genera un reporte del MBR y lo guarda en la ruta especificada
 func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
 	// Crear las carpetas padre si no existen
@@ -18,13 +35,9 @@ func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
 		return err
 	}
 
-	// Obtener el nombre base del archivo sin la extensión
-	dotFileName, outputImage := utils.GetFileNames(path)
-
 	// Definir la paleta de colores
 	primaryColor := "#FFDDC1"     // Color para particiones primarias
 	extendedColor := "#C1E1C1"    // Color para particiones extendidas
-	logicalColor := "#C1D1FF"     // Color para particiones lógicas
 	ebrColor := "#FFD1DC"         // Color para EBR
 	unallocatedColor := "#FFFFFF" // Color para espacios no asignados
 
@@ -39,9 +52,28 @@ func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
                 <tr><td bgcolor="#F5B7B1">mbr_disk_signature</td><td bgcolor="#F5B7B1">%d</td></tr>
             `, mbr.MbrSize, time.Unix(int64(mbr.MbrCreacionDate), 0), mbr.MbrDiskSignature)
 
+	// Datos del reporte JSON
+	data := mbrJSON{
+		Size:         mbr.MbrSize,
+		CreationDate: time.Unix(int64(mbr.MbrCreacionDate), 0).Format(time.RFC3339),
+		Signature:    mbr.MbrDiskSignature,
+		Fit:          strings.TrimRight(string(mbr.MbrDiskFit[:]), "\x00"),
+		Partitions:   []partitionJSON{},
+	}
+
+	// Tabla para el renderizador SVG
+	table := newSvgTable("REPORTE MBR", "#F8D7DA", 2)
+	table.addField("mbr_tamano", mbr.MbrSize, "#F5B7B1")
+	table.addField("mrb_fecha_creacion", time.Unix(int64(mbr.MbrCreacionDate), 0), "#F5B7B1")
+	table.addField("mbr_disk_signature", mbr.MbrDiskSignature, "#F5B7B1")
+
+	// Tablas de los EBR, se agregan después de la tabla del MBR
+	var ebrNodes string
+	var tables []*svgTable
+
 	// Calcular el tamaño total del disco y mantener un seguimiento del espacio no asignado
 	totalSize := mbr.MbrSize
-	allocatedSize := int32(0)
+	allocatedSize := int32(binary.Size(structures.MBR{})) // El MBR ocupa el inicio del disco
 
 	// Agregar las particiones a la tabla
 	for i, part := range mbr.MbrPartitions {
@@ -53,6 +85,7 @@ func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
 				dotContent += fmt.Sprintf(`
                     <tr><td colspan="2" bgcolor="%s"><b>ESPACIO NO ASIGNADO (Tamaño: %d bytes)</b></td></tr>
                 `, unallocatedColor, unallocatedSize)
+				table.addRow(svgCell{text: fmt.Sprintf("ESPACIO NO ASIGNADO (Tamaño: %d bytes)", unallocatedSize), bold: true, bg: unallocatedColor, colspan: 2})
 				allocatedSize += unallocatedSize
 			}
 
@@ -89,51 +122,49 @@ func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
 				rowColor, rowColor, part.Part_size,
 				rowColor, rowColor, partName)
 
+			table.addRow(svgCell{text: fmt.Sprintf("PARTICIÓN %d", i+1), bold: true, bg: rowColor, colspan: 2})
+			table.addField("part_status", string(partStatus), rowColor)
+			table.addField("part_type", string(partType), rowColor)
+			table.addField("part_fit", string(partFit), rowColor)
+			table.addField("part_start", part.Part_start, rowColor)
+			table.addField("part_size", part.Part_size, rowColor)
+			table.addField("part_name", partName, rowColor)
+
 			allocatedSize += part.Part_size
 
-			// Si es una partición extendida, mostrar EBRs y particiones lógicas
+			partData := partitionJSON{
+				Status: string(partStatus),
+				Type:   string(partType),
+				Fit:    string(partFit),
+				Start:  part.Part_start,
+				Size:   part.Part_size,
+				Name:   partName,
+			}
+
+			// Si es una partición extendida, recorrer la cadena de EBR desde su inicio
 			if partType == 'E' {
-				ebrStart := part.Part_start
-				dotContent += fmt.Sprintf(`
-                    <tr><td colspan="2" bgcolor="%s"><b>PART. EXTENDIDA (Inicio: %d)</b></td></tr>
-                `, extendedColor, ebrStart)
-
-				// Iterar sobre los EBRs
-				for ebrStart != -1 {
-					ebr, err := structures.ReadEBR(int32(ebrStart), file)
-					if err != nil {
-						return fmt.Errorf("error al leer EBR: %v", err)
-					}
+				chain, err := structures.ReadEBRChain(part.Part_start, file)
+				if err != nil {
+					return fmt.Errorf("error al leer EBR: %v", err)
+				}
 
-					ebrName := strings.TrimRight(string(ebr.Ebr_name[:]), "\x00")
-					ebrFit := rune(ebr.Ebr_fit[0])
-
-					// Mostrar información del EBR
-					dotContent += fmt.Sprintf(`
-                        <tr><td colspan="2" bgcolor="%s"><b>EBR (Inicio: %d)</b></td></tr>
-                        <tr><td bgcolor="%s">ebr_fit</td><td bgcolor="%s">%c</td></tr>
-                        <tr><td bgcolor="%s">ebr_start</td><td bgcolor="%s">%d</td></tr>
-                        <tr><td bgcolor="%s">ebr_size</td><td bgcolor="%s">%d</td></tr>
-                        <tr><td bgcolor="%s">ebr_next</td><td bgcolor="%s">%d</td></tr>
-                        <tr><td bgcolor="%s">ebr_name</td><td bgcolor="%s">%s</td></tr>
-                    `, ebrColor, ebrStart,
-						ebrColor, ebrColor, ebrFit,
-						ebrColor, ebrColor, ebr.Ebr_start,
-						ebrColor, ebrColor, ebr.Ebr_size,
-						ebrColor, ebrColor, ebr.Ebr_next,
-						ebrColor, ebrColor, ebrName)
-
-					// Si hay una partición lógica después del EBR
-					if ebr.Ebr_size > 0 {
-						dotContent += fmt.Sprintf(`
-                            <tr><td colspan="2" bgcolor="%s"><b>PART. LÓGICA (Inicio: %d)</b></td></tr>
-                        `, logicalColor, ebr.Ebr_start)
+				// Una tabla por cada EBR después de la tabla del MBR
+				for _, ebr := range chain {
+					ebrData := ebrJSON{
+						Mount: strings.TrimRight(string(ebr.Ebr_mount[:]), "\x00"),
+						Fit:   strings.TrimRight(string(ebr.Ebr_fit[:]), "\x00"),
+						Start: ebr.Ebr_start,
+						Size:  ebr.Ebr_size,
+						Next:  ebr.Ebr_next,
+						Name:  strings.TrimRight(string(ebr.Ebr_name[:]), "\x00"),
 					}
-
-					allocatedSize += ebr.Ebr_size
-					ebrStart = int32(ebr.Ebr_next)
+					partData.EBRs = append(partData.EBRs, ebrData)
+					ebrNodes += generateEBRTable(len(partData.EBRs), ebrData, ebrColor)
+					tables = append(tables, ebrSvgTable(len(partData.EBRs), ebrData, ebrColor))
 				}
 			}
+
+			data.Partitions = append(data.Partitions, partData)
 		}
 	}
 
@@ -143,30 +174,89 @@ func ReportMBR(mbr *structures.MBR, path string, file *os.File) error {
 		dotContent += fmt.Sprintf(`
             <tr><td colspan="2" bgcolor="%s"><b>ESPACIO NO ASIGNADO (Tamaño: %d bytes)</b></td></tr>
         `, unallocatedColor, unallocatedSize)
+		table.addRow(svgCell{text: fmt.Sprintf("ESPACIO NO ASIGNADO (Tamaño: %d bytes)", unallocatedSize), bold: true, bg: unallocatedColor, colspan: 2})
 	}
 
-	// Cerrar la tabla y el contenido DOT
-	dotContent += "</table>>] }"
-
-	// Guardar el contenido DOT en un archivo
-	file, err = os.Create(dotFileName)
-	if err != nil {
-		return fmt.Errorf("error al crear el archivo: %v", err)
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(dotContent)
-	if err != nil {
-		return fmt.Errorf("error al escribir en el archivo: %v", err)
+	// Cerrar la tabla del MBR, agregar las tablas de los EBR debajo y cerrar el contenido DOT
+	dotContent += "</table>>];\n" + ebrNodes
+	previous := "tabla"
+	for i := range tables {
+		dotContent += fmt.Sprintf("%s -> ebr%d [style=invis];\n", previous, i+1)
+		previous = fmt.Sprintf("ebr%d", i+1)
 	}
+	dotContent += "}"
+	tables = append([]*svgTable{table}, tables...)
 
-	// Ejecutar el comando Graphviz para generar la imagen
-	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
-	err = cmd.Run()
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{dot: dotContent, data: data, tables: tables})
 	if err != nil {
-		return fmt.Errorf("error al ejecutar Graphviz: %v", err)
+		return err
 	}
 
-	fmt.Println("Imagen de la tabla generada:", outputImage)
+	fmt.Println("Reporte del MBR generado:", path)
 	return nil
 }
+
+// generateEBRTable genera la tabla DOT de un EBR de la partición extendida
+func generateEBRTable(index int, ebr ebrJSON, color string) string {
+	return fmt.Sprintf(`
+        ebr%d [label=<
+            <table border="0" cellborder="1" cellspacing="0">
+                <tr><td colspan="2" bgcolor="%s"><b>EBR %d</b></td></tr>
+                <tr><td bgcolor="%s">ebr_mount</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">ebr_fit</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">ebr_start</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">ebr_size</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">ebr_next</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">ebr_name</td><td bgcolor="%s">%s</td></tr>
+            </table>>];
+`, index, color, index,
+		color, color, html.EscapeString(ebr.Mount),
+		color, color, html.EscapeString(ebr.Fit),
+		color, color, ebr.Start,
+		color, color, ebr.Size,
+		color, color, ebr.Next,
+		color, color, html.EscapeString(ebr.Name))
+}
+
+// ebrSvgTable genera la tabla de un EBR para el renderizador SVG
+func ebrSvgTable(index int, ebr ebrJSON, color string) *svgTable {
+	table := newSvgTable(fmt.Sprintf("EBR %d", index), color, 2)
+	table.addField("ebr_mount", ebr.Mount, color)
+	table.addField("ebr_fit", ebr.Fit, color)
+	table.addField("ebr_start", ebr.Start, color)
+	table.addField("ebr_size", ebr.Size, color)
+	table.addField("ebr_next", ebr.Next, color)
+	table.addField("ebr_name", ebr.Name, color)
+	return table
+}
+
+// mbrJSON representa el MBR en el reporte JSON
+type mbrJSON struct {
+	Size         int32           `json:"size"`
+	CreationDate string          `json:"creation_date"`
+	Signature    int32           `json:"signature"`
+	Fit          string          `json:"fit"`
+	Partitions   []partitionJSON `json:"partitions"`
+}
+
+// partitionJSON representa una partición del MBR en el reporte JSON
+type partitionJSON struct {
+	Status string    `json:"status"`
+	Type   string    `json:"type"`
+	Fit    string    `json:"fit"`
+	Start  int32     `json:"start"`
+	Size   int32     `json:"size"`
+	Name   string    `json:"name"`
+	EBRs   []ebrJSON `json:"ebrs,omitempty"`
+}
+
+// ebrJSON representa un EBR de la partición extendida en el reporte JSON
+type ebrJSON struct {
+	Mount string `json:"mount"`
+	Fit   string `json:"fit"`
+	Start int32  `json:"start"`
+	Size  int32  `json:"size"`
+	Next  int32  `json:"next"`
+	Name  string `json:"name"`
+}
@@ -4,10 +4,20 @@ import (
 	structs "backend/Structs"
 	"backend/utils"
 	"fmt"
-	"os/exec"
 	"time"
 )
 
+func init() {
+	Register(&reportFunc{
+		name:         "sb",
+		description:  "Tabla del superbloque de la partición",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportSuperblock(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
 // ReportSuperblock genera un reporte del Superbloque en formato de tabla
 func ReportSuperblock(superblock *structs.Superblock, diskPath string, path string) error {
 	// Crear las carpetas padre si no existen
@@ -16,40 +26,83 @@ func ReportSuperblock(superblock *structs.Superblock, diskPath string, path stri
 		return fmt.Errorf("error al crear directorios: %v", err)
 	}
 
-	// Obtener el nombre base del archivo sin la extensión
-	dotFileName, outputImage := utils.GetFileNames(path)
-
-	// Inicio del Dot
-	dotContent := initDotGraphForSuperblock(superblock)
-
-	// Crear el archivo DOT
-	err = writeDotFile(dotFileName, dotContent)
-	if err != nil {
-		return err
-	}
-
-	// Ejecutar Graphviz para generar la imagen
-	err = generateSuperblockImage(dotFileName, outputImage)
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{
+		dot:    initDotGraphForSuperblock(superblock),
+		data:   newSuperblockJSON(superblock),
+		tables: []*svgTable{superblockSvgTable(superblock)},
+	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Imagen del Superbloque generada:", outputImage)
+	fmt.Println("Reporte del Superbloque generado:", path)
 	return nil
 }
 
-// generateSuperblockImage genera una imagen a partir del archivo DOT usando Graphviz
-func generateSuperblockImage(dotFileName string, outputImage string) error {
-	// Crear el comando para ejecutar Graphviz
-	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
+// superblockSvgTable genera la tabla del Superbloque para el renderizador SVG
+func superblockSvgTable(superblock *structs.Superblock) *svgTable {
+	table := newSvgTable("REPORTE DEL SUPERBLOQUE", "#4CAF50", 2)
+	bg := "#FFF9C4"
+	table.addField("Cantidad de Inodos", superblock.S_inodes_count, bg)
+	table.addField("Cantidad de Bloques", superblock.S_blocks_count, bg)
+	table.addField("Inodos Libres", superblock.S_free_inodes_count, bg)
+	table.addField("Bloques Libres", superblock.S_free_blocks_count, bg)
+	table.addField("Tamaño de Inodo", fmt.Sprintf("%d bytes", superblock.S_inode_size), bg)
+	table.addField("Tamaño de Bloque", fmt.Sprintf("%d bytes", superblock.S_block_size), bg)
+	table.addField("Primer Inodo Libre", superblock.S_first_ino, bg)
+	table.addField("Primer Bloque Libre", superblock.S_first_blo, bg)
+	table.addField("Inicio Bitmap de Inodos", superblock.S_bm_inode_start, bg)
+	table.addField("Inicio Bitmap de Bloques", superblock.S_bm_block_start, bg)
+	table.addField("Última Modificación", time.Unix(int64(superblock.S_mtime), 0).Format(time.RFC3339), bg)
+	table.addField("Último Montaje", time.Unix(int64(superblock.S_umtime), 0).Format(time.RFC3339), bg)
+	return table
+}
 
-	// Ejecutar el comando y esperar a que termine
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("error al ejecutar Graphviz para generar la imagen del Superbloque: %v", err)
-	}
+// superblockJSON representa el superbloque en el reporte JSON
+type superblockJSON struct {
+	FilesystemType  int32  `json:"filesystem_type"`
+	InodesCount     int32  `json:"inodes_count"`
+	BlocksCount     int32  `json:"blocks_count"`
+	FreeInodesCount int32  `json:"free_inodes_count"`
+	FreeBlocksCount int32  `json:"free_blocks_count"`
+	Mtime           string `json:"mtime"`
+	Umtime          string `json:"umtime"`
+	MntCount        int32  `json:"mnt_count"`
+	Magic           int32  `json:"magic"`
+	InodeSize       int32  `json:"inode_size"`
+	BlockSize       int32  `json:"block_size"`
+	FirstIno        int32  `json:"first_ino"`
+	FirstBlo        int32  `json:"first_blo"`
+	BmInodeStart    int32  `json:"bm_inode_start"`
+	BmBlockStart    int32  `json:"bm_block_start"`
+	InodeStart      int32  `json:"inode_start"`
+	BlockStart      int32  `json:"block_start"`
+	AdminGroup      string `json:"admin_group"`
+}
 
-	return nil
+// newSuperblockJSON convierte el superbloque a su representación JSON
+func newSuperblockJSON(superblock *structs.Superblock) superblockJSON {
+	return superblockJSON{
+		FilesystemType:  superblock.S_filesystem_type,
+		InodesCount:     superblock.S_inodes_count,
+		BlocksCount:     superblock.S_blocks_count,
+		FreeInodesCount: superblock.S_free_inodes_count,
+		FreeBlocksCount: superblock.S_free_blocks_count,
+		Mtime:           time.Unix(int64(superblock.S_mtime), 0).Format(time.RFC3339),
+		Umtime:          time.Unix(int64(superblock.S_umtime), 0).Format(time.RFC3339),
+		MntCount:        superblock.S_mnt_count,
+		Magic:           superblock.S_magic,
+		InodeSize:       superblock.S_inode_size,
+		BlockSize:       superblock.S_block_size,
+		FirstIno:        superblock.S_first_ino,
+		FirstBlo:        superblock.S_first_blo,
+		BmInodeStart:    superblock.S_bm_inode_start,
+		BmBlockStart:    superblock.S_bm_block_start,
+		InodeStart:      superblock.S_inode_start,
+		BlockStart:      superblock.S_block_start,
+		AdminGroup:      superblock.GetAdminGroup(),
+	}
 }
 
 // initDotGraphForSuperblock inicializa el contenido básico del archivo DOT para el Superbloque
@@ -0,0 +1,272 @@
+package reps
+
+import (
+	structs "backend/Structs"
+	"backend/utils"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register(&reportFunc{
+		name:         "tree",
+		description:  "Árbol de inodos y bloques alcanzables desde la raíz",
+		requirements: Requirements{MountedPartition: true, Filesystem: true},
+		generate: func(ctx *ReportContext) error {
+			return ReportTree(ctx.Superblock, ctx.DiskPath, ctx.Path)
+		},
+	})
+}
+
+// treeWalker mantiene el estado del recorrido del árbol de inodos y bloques
+type treeWalker struct {
+	superblock    *structs.Superblock
+	file          *os.File
+	visitedInodes map[int32]bool
+	visitedBlocks map[int32]bool
+	nodes         strings.Builder
+	connections   strings.Builder
+	totalInodes   int32
+	totalBlocks   int32
+	data          treeJSON
+}
+
+// treeJSON representa el árbol de inodos y bloques en el reporte JSON
+type treeJSON struct {
+	Inodes []inodeJSON `json:"inodes"`
+	Blocks []blockJSON `json:"blocks"`
+	Edges  []edgeJSON  `json:"edges"`
+}
+
+// ReportTree genera un reporte con todos los inodos y bloques alcanzables desde el inodo raíz
+func ReportTree(superblock *structs.Superblock, diskPath string, path string) error {
+	// Crear las carpetas padre si no existen
+	err := utils.CreateParentDirs(path)
+	if err != nil {
+		return fmt.Errorf("error al crear directorios: %v", err)
+	}
+
+	// Abrir el archivo de disco
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de disco: %v", err)
+	}
+	defer file.Close()
+
+	// Si no hay inodos, devolver un error
+	if superblock.UsedInodes() == 0 {
+		return fmt.Errorf("no hay inodos en el sistema")
+	}
+
+	walker := &treeWalker{
+		superblock:    superblock,
+		file:          file,
+		visitedInodes: make(map[int32]bool),
+		visitedBlocks: make(map[int32]bool),
+		totalInodes:   superblock.S_inodes_count,
+		totalBlocks:   superblock.S_blocks_count,
+	}
+
+	// Recorrer el árbol desde el inodo raíz
+	err = walker.walkInode(0)
+	if err != nil {
+		return err
+	}
+
+	// Inicio del Dot, nodos y conexiones
+	dotContent := initDotGraph()
+	dotContent += walker.nodes.String()
+	dotContent += walker.connections.String()
+	dotContent += "}" // Fin del Dot
+
+	// Generar el reporte en el formato indicado por la extensión
+	err = writeReport(path, reportOutput{dot: dotContent, data: walker.data})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Reporte del árbol generado:", path)
+	return nil
+}
+
+// walkInode agrega el inodo al grafo y recorre todos sus bloques
+func (w *treeWalker) walkInode(inodeIndex int32) error {
+	if w.visitedInodes[inodeIndex] {
+		return nil
+	}
+	if inodeIndex < 0 || inodeIndex >= w.totalInodes {
+		return fmt.Errorf("índice de inodo fuera de rango: %d", inodeIndex)
+	}
+	w.visitedInodes[inodeIndex] = true
+
+	inode, err := readInode(w.superblock, w.file, inodeIndex)
+	if err != nil {
+		return err
+	}
+
+	// Reutilizar la tabla del reporte de inodos
+	w.nodes.WriteString(generateInodeTable(inodeIndex, inode))
+	w.nodes.WriteString("\n")
+	w.data.Inodes = append(w.data.Inodes, newInodeJSON(inodeIndex, inode))
+
+	isFolder := inode.I_type[0] == '0'
+
+	// Bloques directos
+	for _, blockIndex := range inode.I_block[:12] {
+		if blockIndex == -1 {
+			continue
+		}
+		w.connect(fmt.Sprintf("inode%d", inodeIndex), fmt.Sprintf("block%d", blockIndex))
+		err := w.walkDataBlock(blockIndex, isFolder)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Bloques indirectos simple, doble y triple
+	for level := 1; level <= 3; level++ {
+		blockIndex := inode.I_block[11+level]
+		if blockIndex == -1 {
+			continue
+		}
+		w.connect(fmt.Sprintf("inode%d", inodeIndex), fmt.Sprintf("block%d", blockIndex))
+		err := w.walkPointerBlock(blockIndex, level, isFolder)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkDataBlock agrega un bloque de carpeta o de archivo al grafo
+func (w *treeWalker) walkDataBlock(blockIndex int32, isFolder bool) error {
+	if !w.markBlock(blockIndex) {
+		return nil
+	}
+	blockOffset := int64(w.superblock.S_block_start + blockIndex*w.superblock.S_block_size)
+
+	if !isFolder {
+		fileBlock := w.superblock.NewFileBlock()
+		err := fileBlock.Decode(w.file, blockOffset)
+		if err != nil {
+			return fmt.Errorf("error al decodificar bloque de archivo %d: %w", blockIndex, err)
+		}
+		w.nodes.WriteString(generateFileBlockTable(blockIndex, fileBlock))
+		w.data.Blocks = append(w.data.Blocks, newFileBlockJSON(blockIndex, fileBlock))
+		return nil
+	}
+
+	folderBlock := &structs.FolderBlock{}
+	err := folderBlock.Decode(w.file, blockOffset)
+	if err != nil {
+		return fmt.Errorf("error al decodificar bloque de carpeta %d: %w", blockIndex, err)
+	}
+	w.nodes.WriteString(generateFolderBlockTable(blockIndex, folderBlock))
+	w.data.Blocks = append(w.data.Blocks, newFolderBlockJSON(blockIndex, folderBlock))
+
+	// Recorrer los inodos hijos, evitando . y ..
+	for _, content := range folderBlock.B_content {
+		name := cleanBlockName(content.B_name)
+		if content.B_inodo == -1 || name == "." || name == ".." {
+			continue
+		}
+		w.connect(fmt.Sprintf("block%d", blockIndex), fmt.Sprintf("inode%d", content.B_inodo))
+		err := w.walkInode(content.B_inodo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkPointerBlock agrega un bloque de apuntadores y recorre los bloques a los que apunta
+func (w *treeWalker) walkPointerBlock(blockIndex int32, level int, isFolder bool) error {
+	if !w.markBlock(blockIndex) {
+		return nil
+	}
+
+	pointerBlock := &structs.PointerBlock{}
+	err := pointerBlock.Decode(w.file, int64(w.superblock.S_block_start+blockIndex*w.superblock.S_block_size))
+	if err != nil {
+		return fmt.Errorf("error al decodificar bloque de apuntadores %d: %w", blockIndex, err)
+	}
+	w.nodes.WriteString(generatePointerBlockTable(blockIndex, pointerBlock))
+	w.data.Blocks = append(w.data.Blocks, newPointerBlockJSON(blockIndex, pointerBlock))
+
+	for _, pointer := range pointerBlock.B_pointers {
+		// El bloque 0 siempre pertenece a la raíz, por lo que 0 y -1 indican apuntadores libres
+		if pointer <= 0 {
+			continue
+		}
+		child := pointer
+		w.connect(fmt.Sprintf("block%d", blockIndex), fmt.Sprintf("block%d", child))
+		if level == 1 {
+			err = w.walkDataBlock(child, isFolder)
+		} else {
+			err = w.walkPointerBlock(child, level-1, isFolder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connect agrega una conexión entre dos nodos del grafo
+func (w *treeWalker) connect(from string, to string) {
+	fmt.Fprintf(&w.connections, "%s -> %s;\n", from, to)
+	w.data.Edges = append(w.data.Edges, edgeJSON{From: from, To: to})
+}
+
+// markBlock marca un bloque como visitado, devuelve false si ya fue visitado o está fuera de rango
+func (w *treeWalker) markBlock(blockIndex int32) bool {
+	if w.visitedBlocks[blockIndex] || blockIndex < 0 || blockIndex >= w.totalBlocks {
+		return false
+	}
+	w.visitedBlocks[blockIndex] = true
+	return true
+}
+
+// generateFolderBlockTable genera la tabla de un bloque de carpeta en formato DOT
+func generateFolderBlockTable(blockIndex int32, block *structs.FolderBlock) string {
+	table := fmt.Sprintf(`block%d [label=<
+		<table border="0" cellborder="1" cellspacing="0" cellpadding="4" bgcolor="#FFFDE7" style="rounded">
+			<tr><td colspan="2" bgcolor="#FF9800" align="center"><b>BLOQUE CARPETA %d</b></td></tr>
+			<tr><td><b>b_name</b></td><td><b>b_inodo</b></td></tr>
+	`, blockIndex, blockIndex)
+
+	for _, content := range block.B_content {
+		table += fmt.Sprintf("<tr><td>%s</td><td>%d</td></tr>", html.EscapeString(cleanBlockName(content.B_name)), content.B_inodo)
+	}
+
+	table += "</table>>];\n"
+	return table
+}
+
+// generateFileBlockTable genera la tabla de un bloque de archivo en formato DOT
+func generateFileBlockTable(blockIndex int32, block *structs.FileBlock) string {
+	content := strings.ReplaceAll(html.EscapeString(block.GetContent()), "\n", "<br/>")
+	return fmt.Sprintf(`block%d [label=<
+		<table border="0" cellborder="1" cellspacing="0" cellpadding="4" bgcolor="#FFFDE7" style="rounded">
+			<tr><td bgcolor="#2196F3" align="center"><b>BLOQUE ARCHIVO %d</b></td></tr>
+			<tr><td>%s</td></tr>
+		</table>>];
+`, blockIndex, blockIndex, content)
+}
+
+// generatePointerBlockTable genera la tabla de un bloque de apuntadores en formato DOT
+func generatePointerBlockTable(blockIndex int32, block *structs.PointerBlock) string {
+	pointers := make([]string, 0, len(block.B_pointers))
+	for _, pointer := range block.B_pointers {
+		pointers = append(pointers, fmt.Sprintf("%d", pointer))
+	}
+	return fmt.Sprintf(`block%d [label=<
+		<table border="0" cellborder="1" cellspacing="0" cellpadding="4" bgcolor="#FFFDE7" style="rounded">
+			<tr><td bgcolor="#9C27B0" align="center"><b>BLOQUE APUNTADORES %d</b></td></tr>
+			<tr><td>%s</td></tr>
+		</table>>];
+`, blockIndex, blockIndex, strings.Join(pointers, ", "))
+}
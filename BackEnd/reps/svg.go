@@ -0,0 +1,160 @@
+package reps
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Medidas usadas para dibujar las tablas SVG sin depender de Graphviz
+const (
+	svgCharWidth   = 7.5 // Ancho aproximado de un carácter monoespaciado de 12px
+	svgRowHeight   = 24  // Alto de cada fila
+	svgCellPadding = 8   // Espacio horizontal a cada lado del texto
+	svgMargin      = 10  // Margen exterior del documento
+	svgTableGap    = 20  // Separación vertical entre tablas
+)
+
+// svgCell representa una celda de una tabla SVG
+type svgCell struct {
+	text    string // Texto de la celda
+	bold    bool   // Indica si el texto va en negrita
+	bg      string // Color de fondo, vacío para blanco
+	colspan int    // Cantidad de columnas que ocupa, 0 se toma como 1
+}
+
+// svgTable representa una tabla que se dibuja de forma nativa en SVG
+type svgTable struct {
+	rows [][]svgCell
+}
+
+// newSvgTable crea una tabla con una fila de título que ocupa todas las columnas
+func newSvgTable(title string, titleColor string, columns int) *svgTable {
+	table := &svgTable{}
+	table.addRow(svgCell{text: title, bold: true, bg: titleColor, colspan: columns})
+	return table
+}
+
+// addRow agrega una fila a la tabla
+func (t *svgTable) addRow(cells ...svgCell) {
+	t.rows = append(t.rows, cells)
+}
+
+// addField agrega una fila de dos columnas con la etiqueta en negrita y su valor
+func (t *svgTable) addField(label string, value interface{}, bg string) {
+	t.addRow(svgCell{text: label, bold: true, bg: bg}, svgCell{text: fmt.Sprint(value), bg: bg})
+}
+
+// columnWidths calcula el ancho de cada columna según el texto más largo que contiene
+func (t *svgTable) columnWidths() []float64 {
+	columns := 0
+	for _, row := range t.rows {
+		count := 0
+		for _, c := range row {
+			count += span(c)
+		}
+		columns = max(columns, count)
+	}
+
+	widths := make([]float64, columns)
+	// Primero las celdas simples, luego las que ocupan varias columnas
+	for _, multi := range []bool{false, true} {
+		for _, row := range t.rows {
+			col := 0
+			for _, c := range row {
+				n := span(c)
+				if (n > 1) == multi {
+					needed := textWidth(c.text)
+					current := 0.0
+					for i := col; i < col+n; i++ {
+						current += widths[i]
+					}
+					if needed > current {
+						widths[col+n-1] += needed - current // El excedente va a la última columna
+					}
+				}
+				col += n
+			}
+		}
+	}
+	return widths
+}
+
+// render dibuja la tabla en la posición indicada y devuelve su alto
+func (t *svgTable) render(builder *strings.Builder, x float64, y float64) float64 {
+	widths := t.columnWidths()
+	for rowIndex, row := range t.rows {
+		cellX := x
+		cellY := y + float64(rowIndex*svgRowHeight)
+		col := 0
+		for _, c := range row {
+			width := 0.0
+			for i := col; i < col+span(c) && i < len(widths); i++ {
+				width += widths[i]
+			}
+			bg := c.bg
+			if bg == "" {
+				bg = "#FFFFFF"
+			}
+			weight := "normal"
+			if c.bold {
+				weight = "bold"
+			}
+			fmt.Fprintf(builder, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s" stroke="#555555"/>`+"\n",
+				cellX, cellY, width, svgRowHeight, bg)
+			fmt.Fprintf(builder, `<text x="%.1f" y="%.1f" font-weight="%s">%s</text>`+"\n",
+				cellX+svgCellPadding, cellY+svgRowHeight*0.7, weight, html.EscapeString(singleLine(c.text)))
+			cellX += width
+			col += span(c)
+		}
+	}
+	return float64(len(t.rows) * svgRowHeight)
+}
+
+// width devuelve el ancho total de la tabla
+func (t *svgTable) width() float64 {
+	total := 0.0
+	for _, w := range t.columnWidths() {
+		total += w
+	}
+	return total
+}
+
+// renderSvg genera un documento SVG con las tablas apiladas verticalmente
+func renderSvg(tables []*svgTable) string {
+	var body strings.Builder
+	y := float64(svgMargin)
+	maxWidth := 0.0
+	for i, table := range tables {
+		if i > 0 {
+			y += svgTableGap
+		}
+		y += table.render(&body, svgMargin, y)
+		maxWidth = max(maxWidth, table.width())
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="monospace" font-size="12">
+<rect width="100%%" height="100%%" fill="#FAFAFA"/>
+%s</svg>
+`, maxWidth+2*svgMargin, y+svgMargin, body.String())
+}
+
+// span devuelve la cantidad de columnas que ocupa una celda
+func span(c svgCell) int {
+	if c.colspan < 1 {
+		return 1
+	}
+	return c.colspan
+}
+
+// textWidth calcula el ancho necesario para el texto de una celda
+func textWidth(text string) float64 {
+	return float64(utf8.RuneCountInString(singleLine(text)))*svgCharWidth + 2*svgCellPadding
+}
+
+// singleLine reemplaza los saltos de línea, las celdas SVG se dibujan en una sola línea
+func singleLine(text string) string {
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,616 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Cada comando que usa un disco trabaja sobre un caché de páginas del disco durante su ejecución
+// Las lecturas se sirven desde el caché y las escrituras modifican las páginas en memoria, marcándolas como sucias
+// Al terminar el comando se aplican juntas las páginas sucias, o ninguna si el comando falla
+// Al confirmar, primero se escribe un registro (write-ahead log) junto al disco con todas las escrituras,
+// luego se aplican sobre el disco y por último se elimina el registro
+// Si el proceso termina mientras se aplican, el registro sigue completo y se vuelve a aplicar al recuperar el disco
+
+// pageSize es el tamaño de las páginas del caché
+const pageSize = 4096
+
+// walMagic y walEnd delimitan el registro, walEnd va seguido del CRC32 del contenido
+var (
+	walMagic = [4]byte{'W', 'A', 'L', '1'}
+	walEnd   = [4]byte{'E', 'N', 'D', '1'}
+)
+
+// pendingWrite es una escritura pendiente de aplicar en el disco
+type pendingWrite struct {
+	offset int64  // Posición en el disco
+	data   []byte // Datos a escribir, nil si es un rango de ceros
+	zeros  int64  // Cantidad de ceros a escribir cuando data es nil
+}
+
+// length devuelve la cantidad de bytes que ocupa la escritura
+func (w *pendingWrite) length() int64 {
+	if w.data == nil {
+		return w.zeros
+	}
+	return int64(len(w.data))
+}
+
+// cachePage es una página del disco guardada en memoria
+type cachePage struct {
+	data  []byte // Contenido de la página, siempre de pageSize bytes
+	size  int    // Bytes válidos, menor a pageSize si el disco termina dentro de la página
+	dirty bool   // La página tiene cambios sin aplicar en el disco
+}
+
+// zeroRange es un rango del disco que se llenará con ceros, se guarda sin reservar memoria para los ceros
+type zeroRange struct {
+	offset int64
+	length int64
+}
+
+// Transaction agrupa las lecturas y escrituras de un comando sobre un disco
+// Los comandos de solo lectura pueden compartir la transacción porque no modifican el caché del disco
+type Transaction struct {
+	path     string // Path del disco
+	readOnly bool   // La transacción no admite escrituras
+	refs     int    // Comandos que comparten la transacción de solo lectura
+
+	mutex  sync.Mutex           // Protege el caché, los comandos de solo lectura se ejecutan en paralelo
+	pages  map[int64]*cachePage // Páginas en memoria por número de página
+	zeros  []zeroRange          // Rangos de ceros pendientes, se aplican antes que las páginas sucias
+	values map[any]any          // Datos leídos del disco que se conservan mientras dura la transacción, como los bitmaps
+
+	afterCommit []func() error // Cambios fuera del disco que solo se aplican si se confirman las escrituras
+}
+
+// ErrAfterCommit indica que las escrituras se confirmaron pero falló un cambio registrado con AfterCommit
+var ErrAfterCommit = errors.New("los cambios se guardaron en el disco pero no se pudo actualizar el estado del servidor")
+
+var (
+	transactionsMutex sync.Mutex
+	transactions      = make(map[string]*Transaction) // Transacción activa de cada disco
+)
+
+// DiskKey normaliza el path de un disco a un path absoluto, el mismo disco puede escribirse
+// relativo al directorio del servidor, con "./" o con "//"
+// Las transacciones, los candados, el registro de discos, la tabla de montajes y las letras usan esta clave
+func DiskKey(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path) // Solo falla si no se puede obtener el directorio actual
+	}
+	return abs
+}
+
+// walPath devuelve el path del registro de escrituras del disco
+func walPath(path string) string {
+	return DiskKey(path) + ".wal"
+}
+
+// BeginTransaction inicia una transacción sobre el disco, desde ese momento las escrituras quedan pendientes
+// Antes de iniciarla se recupera una transacción que haya quedado a medias
+func BeginTransaction(path string) (*Transaction, error) {
+	_, err := RecoverTransaction(path)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionsMutex.Lock()
+	defer transactionsMutex.Unlock()
+
+	key := DiskKey(path)
+	if _, exists := transactions[key]; exists {
+		return nil, fmt.Errorf("ya hay una transacción activa en el disco %s", path)
+	}
+	tx := &Transaction{path: key, refs: 1, pages: make(map[int64]*cachePage), values: make(map[any]any)}
+	transactions[key] = tx
+	return tx, nil
+}
+
+// BeginReadTransaction inicia una transacción de solo lectura sobre el disco para usar su caché
+// Si otro comando de lectura ya tiene una sobre el mismo disco se comparte
+// El primer comando de lectura recupera la transacción que haya quedado a medias, así nunca se lee un disco a medio escribir
+func BeginReadTransaction(path string) (*Transaction, error) {
+	transactionsMutex.Lock()
+	defer transactionsMutex.Unlock()
+
+	key := DiskKey(path)
+	if tx, exists := transactions[key]; exists {
+		if !tx.readOnly {
+			return nil, fmt.Errorf("ya hay una transacción activa en el disco %s", path)
+		}
+		tx.refs++
+		return tx, nil
+	}
+
+	// Se recupera con transactionsMutex tomado para que dos lecturas en paralelo no apliquen el registro a la vez
+	_, err := RecoverTransaction(path)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Transaction{path: key, readOnly: true, refs: 1, pages: make(map[int64]*cachePage), values: make(map[any]any)}
+	transactions[key] = tx
+	return tx, nil
+}
+
+// activeTransaction devuelve la transacción activa del disco abierto en file, nil si no hay
+func activeTransaction(file *os.File) *Transaction {
+	transactionsMutex.Lock()
+	defer transactionsMutex.Unlock()
+	return transactions[DiskKey(file.Name())]
+}
+
+// end libera la transacción, deja de estar activa cuando ningún comando la usa
+func (tx *Transaction) end() {
+	transactionsMutex.Lock()
+	defer transactionsMutex.Unlock()
+	tx.refs--
+	if tx.refs > 0 {
+		return
+	}
+	if transactions[tx.path] == tx {
+		delete(transactions, tx.path)
+	}
+}
+
+// Rollback descarta las escrituras pendientes, el disco queda como antes de iniciar la transacción
+// En una transacción de solo lectura solo la libera
+func (tx *Transaction) Rollback() {
+	tx.end()
+	if tx.readOnly {
+		return
+	}
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.pages = make(map[int64]*cachePage)
+	tx.values = make(map[any]any)
+	tx.zeros = nil
+	tx.afterCommit = nil
+}
+
+// Commit aplica las escrituras pendientes en el disco y termina la transacción
+// Después ejecuta los cambios registrados con AfterCommit, si alguno falla el error incluye ErrAfterCommit
+func (tx *Transaction) Commit() error {
+	tx.end()
+	if tx.readOnly {
+		return nil
+	}
+	err := tx.flush()
+	if err != nil {
+		return err
+	}
+
+	tx.mutex.Lock()
+	afterCommit := tx.afterCommit
+	tx.afterCommit = nil
+	tx.mutex.Unlock()
+
+	var errs []error
+	for _, fn := range afterCommit {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrAfterCommit, errors.Join(errs...))
+	}
+	return nil
+}
+
+// AfterCommit registra fn para ejecutarla cuando se confirme la transacción activa del disco, se descarta si se revierte
+// Lo usan los comandos que además de escribir en el disco cambian el estado del servidor, como la tabla de montajes
+// Sin una transacción de escritura activa fn se ejecuta de inmediato y se devuelve su error
+func AfterCommit(path string, fn func() error) error {
+	transactionsMutex.Lock()
+	tx := transactions[DiskKey(path)]
+	transactionsMutex.Unlock()
+	if tx == nil || tx.readOnly {
+		return fn()
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+	return nil
+}
+
+// Flush aplica en el disco las escrituras pendientes del disco abierto en file sin terminar su transacción
+// Lo usan los comandos que necesitan medir el tiempo real de escritura, como mkfs
+func Flush(file *os.File) error {
+	tx := activeTransaction(file)
+	if tx == nil || tx.readOnly {
+		return nil
+	}
+	return tx.flush()
+}
+
+// flush guarda el registro, aplica los rangos de ceros y las páginas sucias y deja las páginas limpias en el caché
+func (tx *Transaction) flush() error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	writes := tx.pendingWrites()
+	if len(writes) == 0 {
+		return nil
+	}
+
+	// Guardar el registro antes de modificar el disco
+	err := writeWAL(walPath(tx.path), writes)
+	if err != nil {
+		os.Remove(walPath(tx.path)) // El disco no se modificó, el registro incompleto no sirve
+		return err
+	}
+
+	err = applyWrites(tx.path, writes)
+	if err != nil {
+		// El registro se conserva para completar las escrituras al recuperar el disco
+		return fmt.Errorf("error al aplicar la transacción, se completará al volver a usar el disco: %w", err)
+	}
+
+	tx.zeros = nil
+	for _, page := range tx.pages {
+		page.dirty = false
+	}
+	err = os.Remove(walPath(tx.path))
+	if err != nil {
+		return fmt.Errorf("error al eliminar el registro de la transacción: %w", err)
+	}
+	return nil
+}
+
+// pendingWrites devuelve los rangos de ceros seguidos de las páginas sucias ordenadas por posición
+// Las páginas guardan su contenido completo, por eso se aplican después de los ceros
+func (tx *Transaction) pendingWrites() []pendingWrite {
+	writes := make([]pendingWrite, 0, len(tx.zeros))
+	for _, z := range tx.zeros {
+		writes = append(writes, pendingWrite{offset: z.offset, zeros: z.length})
+	}
+
+	var dirty []int64
+	for index, page := range tx.pages {
+		if page.dirty {
+			dirty = append(dirty, index)
+		}
+	}
+	slices.Sort(dirty)
+	for _, index := range dirty {
+		page := tx.pages[index]
+		writes = append(writes, pendingWrite{offset: index * pageSize, data: page.data[:page.size]})
+	}
+	return writes
+}
+
+// TransactionValue devuelve el valor guardado con key en la transacción activa del disco abierto en file
+// key debe ser comparable, por ejemplo un struct propio del paquete que guarda el valor
+// La primera vez lo obtiene con load, sin transacción activa siempre se usa load
+// Quien modifica el valor debe escribir los cambios en el disco, la transacción solo lo conserva
+func TransactionValue(file *os.File, key any, load func() (any, error)) (any, error) {
+	tx := activeTransaction(file)
+	if tx == nil {
+		return load()
+	}
+
+	tx.mutex.Lock()
+	value, exists := tx.values[key]
+	tx.mutex.Unlock()
+	if exists {
+		return value, nil
+	}
+
+	// load lee del disco a través del caché, no se puede llamar con tx.mutex tomado
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	if current, exists := tx.values[key]; exists {
+		return current, nil // Otro comando de lectura lo cargó primero
+	}
+	tx.values[key] = value
+	return value, nil
+}
+
+// DropTransactionValue descarta el valor guardado con key en la transacción activa del disco abierto en file
+func DropTransactionValue(file *os.File, key any) {
+	tx := activeTransaction(file)
+	if tx == nil {
+		return
+	}
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	delete(tx.values, key)
+}
+
+// page devuelve la página del caché y la carga del disco si no está en memoria, requiere tx.mutex tomado
+func (tx *Transaction) page(file *os.File, index int64) (*cachePage, error) {
+	if page, exists := tx.pages[index]; exists {
+		return page, nil
+	}
+
+	page := &cachePage{data: make([]byte, pageSize)}
+	n, err := file.ReadAt(page.data, index*pageSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	page.size = n
+
+	// Los rangos de ceros pendientes todavía no están en el disco
+	start := index * pageSize
+	for _, z := range tx.zeros {
+		from := max(start, z.offset)
+		to := min(start+pageSize, z.offset+z.length)
+		if from < to {
+			clear(page.data[from-start : to-start])
+			page.size = max(page.size, int(to-start))
+		}
+	}
+
+	tx.pages[index] = page
+	return page, nil
+}
+
+// read llena buffer con los bytes del disco a partir de offset usando el caché
+func (tx *Transaction) read(file *os.File, offset int64, buffer []byte) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	for done := 0; done < len(buffer); {
+		position := offset + int64(done)
+		page, err := tx.page(file, position/pageSize)
+		if err != nil {
+			return err
+		}
+		start := int(position % pageSize)
+		if start >= page.size {
+			return io.EOF
+		}
+		done += copy(buffer[done:], page.data[start:page.size])
+	}
+	return nil
+}
+
+// write copia data en las páginas del caché a partir de offset y las marca como sucias
+func (tx *Transaction) write(file *os.File, offset int64, data []byte) error {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if tx.readOnly {
+		return tx.writeThrough(file, offset, data)
+	}
+
+	for done := 0; done < len(data); {
+		position := offset + int64(done)
+		index := position / pageSize
+		start := int(position % pageSize)
+		length := min(len(data)-done, pageSize-start)
+
+		var page *cachePage
+		if _, exists := tx.pages[index]; !exists && length == pageSize {
+			// La escritura cubre la página completa, no hace falta leerla del disco
+			page = &cachePage{data: make([]byte, pageSize)}
+			tx.pages[index] = page
+		} else {
+			var err error
+			page, err = tx.page(file, index)
+			if err != nil {
+				return err
+			}
+		}
+
+		copy(page.data[start:], data[done:done+length])
+		page.size = max(page.size, start+length)
+		page.dirty = true
+		done += length
+	}
+	return nil
+}
+
+// writeThrough escribe data directo en el disco y actualiza las páginas en memoria, requiere tx.mutex tomado
+// Los comandos de lectura solo escriben la fecha de acceso de los inodos, que no necesita ser atómica
+func (tx *Transaction) writeThrough(file *os.File, offset int64, data []byte) error {
+	_, err := file.WriteAt(data, offset)
+	if err != nil {
+		return err
+	}
+
+	end := offset + int64(len(data))
+	for index, page := range tx.pages {
+		start := index * pageSize
+		from := max(start, offset)
+		to := min(start+pageSize, end)
+		if from < to {
+			copy(page.data[from-start:to-start], data[from-offset:to-offset])
+			page.size = max(page.size, int(to-start))
+		}
+	}
+	return nil
+}
+
+// zero registra un rango de ceros y lo aplica sobre las páginas en memoria
+// Las páginas que quedan cubiertas por completo se descartan, se vuelven a leer con los ceros aplicados
+func (tx *Transaction) zero(offset int64, length int64) error {
+	if tx.readOnly {
+		return fmt.Errorf("el disco %s está abierto solo para lectura", tx.path)
+	}
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	end := offset + length
+	for index, page := range tx.pages {
+		start := index * pageSize
+		from := max(start, offset)
+		to := min(start+pageSize, end)
+		if from >= to {
+			continue
+		}
+		if from == start && to == start+pageSize {
+			delete(tx.pages, index)
+			continue
+		}
+		clear(page.data[from-start : to-start])
+		page.size = max(page.size, int(to-start))
+		page.dirty = true
+	}
+	tx.zeros = append(tx.zeros, zeroRange{offset: offset, length: length})
+	return nil
+}
+
+// applyWrites escribe en el disco las escrituras en orden y sincroniza el archivo
+func applyWrites(path string, writes []pendingWrite) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for i := range writes {
+		w := &writes[i]
+		if w.data == nil {
+			err = zeroFile(file, w.offset, w.zeros)
+		} else {
+			_, err = file.WriteAt(w.data, w.offset)
+		}
+		if err != nil {
+			return fmt.Errorf("error escribiendo en la posición %d: %w", w.offset, err)
+		}
+	}
+	return file.Sync()
+}
+
+// writeWAL guarda las escrituras en el registro y lo sincroniza con el disco
+func writeWAL(path string, writes []pendingWrite) error {
+	var body bytes.Buffer
+	body.Write(walMagic[:])
+	binary.Write(&body, binary.LittleEndian, uint32(len(writes)))
+	for i := range writes {
+		w := &writes[i]
+		isZero := uint8(0)
+		if w.data == nil {
+			isZero = 1
+		}
+		binary.Write(&body, binary.LittleEndian, w.offset)
+		binary.Write(&body, binary.LittleEndian, w.length())
+		body.WriteByte(isZero)
+		body.Write(w.data)
+	}
+	checksum := crc32.ChecksumIEEE(body.Bytes())
+	body.Write(walEnd[:])
+	binary.Write(&body, binary.LittleEndian, checksum)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error al crear el registro de la transacción: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(body.Bytes())
+	if err != nil {
+		return fmt.Errorf("error al escribir el registro de la transacción: %w", err)
+	}
+	err = file.Sync()
+	if err != nil {
+		return fmt.Errorf("error al sincronizar el registro de la transacción: %w", err)
+	}
+	return nil
+}
+
+// readWAL lee las escrituras del registro, devuelve error si el registro está incompleto o dañado
+func readWAL(data []byte) ([]pendingWrite, error) {
+	trailer := len(walEnd) + 4
+	if len(data) < len(walMagic)+4+trailer {
+		return nil, errors.New("registro incompleto")
+	}
+	body := data[:len(data)-trailer]
+	if !bytes.Equal(body[:len(walMagic)], walMagic[:]) || !bytes.Equal(data[len(body):len(body)+len(walEnd)], walEnd[:]) {
+		return nil, errors.New("registro con formato inválido")
+	}
+	if binary.LittleEndian.Uint32(data[len(data)-4:]) != crc32.ChecksumIEEE(body) {
+		return nil, errors.New("el checksum del registro no coincide")
+	}
+
+	reader := bytes.NewReader(body[len(walMagic):])
+	var count uint32
+	err := binary.Read(reader, binary.LittleEndian, &count)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := make([]pendingWrite, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var w pendingWrite
+		var length int64
+		var isZero uint8
+		err = binary.Read(reader, binary.LittleEndian, &w.offset)
+		if err == nil {
+			err = binary.Read(reader, binary.LittleEndian, &length)
+		}
+		if err == nil {
+			err = binary.Read(reader, binary.LittleEndian, &isZero)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("registro truncado: %w", err)
+		}
+
+		if isZero == 1 {
+			w.zeros = length
+		} else {
+			w.data = make([]byte, length)
+			_, err = io.ReadFull(reader, w.data)
+			if err != nil {
+				return nil, fmt.Errorf("registro truncado: %w", err)
+			}
+		}
+		writes = append(writes, w)
+	}
+	return writes, nil
+}
+
+// RecoverTransaction completa la transacción que quedó a medias en el disco, si existe su registro
+// Un registro incompleto indica que el disco no llegó a modificarse y se descarta
+// Devuelve true si se aplicaron escrituras
+func RecoverTransaction(path string) (bool, error) {
+	data, err := os.ReadFile(walPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error al leer el registro de transacción de %s: %w", path, err)
+	}
+
+	writes, err := readWAL(data)
+	if err != nil {
+		// El registro quedó incompleto antes de modificar el disco, no hay nada que recuperar
+		return false, os.Remove(walPath(path))
+	}
+
+	err = applyWrites(path, writes)
+	if err != nil {
+		return false, fmt.Errorf("error al recuperar la transacción de %s: %w", path, err)
+	}
+	return true, os.Remove(walPath(path))
+}
+
+// zeroFile escribe length ceros en el archivo a partir de offset usando un buffer de 1 MB
+func zeroFile(file *os.File, offset int64, length int64) error {
+	buffer := make([]byte, min(length, 1024*1024))
+	for length > 0 {
+		writeSize := min(length, int64(len(buffer)))
+		if _, err := file.WriteAt(buffer[:writeSize], offset); err != nil {
+			return err
+		}
+		offset += writeSize
+		length -= writeSize
+	}
+	return nil
+}
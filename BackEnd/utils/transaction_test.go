@@ -0,0 +1,367 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestImage crea una imagen .mia temporal de size bytes llena de ceros
+func newTestImage(t testing.TB, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "disco.mia")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	err = file.Truncate(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecoverTransaction(t *testing.T) {
+	data := bytes.Repeat([]byte{0xAB}, 3*pageSize)
+	writes := []pendingWrite{
+		{offset: 0, zeros: pageSize},
+		{offset: pageSize / 2, data: data},
+	}
+
+	tests := []struct {
+		name          string
+		prepare       func(t *testing.T, disk string) // Deja el disco y su registro como si el proceso se hubiera detenido
+		wantRecovered bool
+	}{
+		{
+			name: "registro completo sin aplicar",
+			prepare: func(t *testing.T, disk string) {
+				if err := writeWAL(walPath(disk), writes); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantRecovered: true,
+		},
+		{
+			name: "registro completo aplicado a medias",
+			prepare: func(t *testing.T, disk string) {
+				if err := writeWAL(walPath(disk), writes); err != nil {
+					t.Fatal(err)
+				}
+				// Solo llegó al disco la primera página de los datos
+				if err := applyWrites(disk, []pendingWrite{{offset: pageSize / 2, data: data[:pageSize]}}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantRecovered: true,
+		},
+		{
+			name: "registro cortado antes de terminar",
+			prepare: func(t *testing.T, disk string) {
+				if err := writeWAL(walPath(disk), writes); err != nil {
+					t.Fatal(err)
+				}
+				info, err := os.Stat(walPath(disk))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Truncate(walPath(disk), info.Size()-10); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "registro con checksum incorrecto",
+			prepare: func(t *testing.T, disk string) {
+				if err := writeWAL(walPath(disk), writes); err != nil {
+					t.Fatal(err)
+				}
+				wal, err := os.ReadFile(walPath(disk))
+				if err != nil {
+					t.Fatal(err)
+				}
+				wal[len(walMagic)+10] ^= 0xFF
+				if err := os.WriteFile(walPath(disk), wal, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := newTestImage(t, 4*pageSize)
+			tt.prepare(t, disk)
+			before, err := os.ReadFile(disk)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			recovered, err := RecoverTransaction(disk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recovered != tt.wantRecovered {
+				t.Fatalf("RecoverTransaction() = %v, se esperaba %v", recovered, tt.wantRecovered)
+			}
+			if _, err := os.Stat(walPath(disk)); !os.IsNotExist(err) {
+				t.Fatalf("el registro sigue en el disco: %v", err)
+			}
+
+			after, err := os.ReadFile(disk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantRecovered {
+				// Un registro incompleto se descarta sin tocar el disco
+				if !bytes.Equal(before, after) {
+					t.Fatal("el disco cambió al descartar un registro incompleto")
+				}
+				return
+			}
+			want := make([]byte, 4*pageSize)
+			copy(want[pageSize/2:], data)
+			if !bytes.Equal(after, want) {
+				t.Fatal("el disco no quedó con todas las escrituras del registro")
+			}
+		})
+	}
+}
+
+func TestBeginReadTransactionRecovers(t *testing.T) {
+	disk := newTestImage(t, 2*pageSize)
+	err := writeWAL(walPath(disk), []pendingWrite{{offset: 100, data: []byte("recuperado")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := BeginReadTransaction(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	file, err := os.Open(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// La lectura debe ver las escrituras del registro, no el disco a medio escribir
+	got := make([]byte, len("recuperado"))
+	err = ReadBytes(file, 100, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "recuperado" {
+		t.Fatalf("ReadBytes() = %q, se esperaba %q", got, "recuperado")
+	}
+	if _, err := os.Stat(walPath(disk)); !os.IsNotExist(err) {
+		t.Fatalf("el registro sigue en el disco: %v", err)
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	disk := newTestImage(t, pageSize)
+
+	// Sin transacción el cambio se aplica de inmediato
+	applied := false
+	if err := AfterCommit(disk, func() error { applied = true; return nil }); err != nil || !applied {
+		t.Fatalf("AfterCommit() sin transacción: aplicado = %v, error = %v", applied, err)
+	}
+
+	tests := []struct {
+		name        string
+		commit      bool
+		fnErr       error
+		wantApplied bool
+	}{
+		{name: "confirmada", commit: true, wantApplied: true},
+		{name: "revertida"},
+		{name: "el cambio falla", commit: true, fnErr: errors.New("sin espacio"), wantApplied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := BeginTransaction(disk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			applied := false
+			err = AfterCommit(disk, func() error { applied = true; return tt.fnErr })
+			if err != nil || applied {
+				tx.Rollback()
+				t.Fatalf("AfterCommit() con transacción: aplicado = %v, error = %v", applied, err)
+			}
+
+			if !tt.commit {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if !errors.Is(err, ErrAfterCommit) != (tt.fnErr == nil) || (tt.fnErr != nil && !errors.Is(err, tt.fnErr)) {
+					t.Fatalf("Commit() error = %v, se esperaba %v", err, tt.fnErr)
+				}
+			}
+			if applied != tt.wantApplied {
+				t.Fatalf("aplicado = %v, se esperaba %v", applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestDiskKey(t *testing.T) {
+	// Los paths relativos se resuelven desde el directorio del servidor
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	dir, err = os.Getwd() // TempDir puede estar bajo un enlace simbólico
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "disco.mia")
+	for _, path := range []string{
+		"disco.mia",
+		"./disco.mia",
+		"sub/../disco.mia",
+		want,
+		dir + "//disco.mia",
+		dir + "/./sub/../disco.mia",
+	} {
+		if got := DiskKey(path); got != want {
+			t.Errorf("DiskKey(%q) = %q, se esperaba %q", path, got, want)
+		}
+	}
+	if DiskKey("otro.mia") == want {
+		t.Fatal("dos discos distintos tienen la misma clave")
+	}
+
+	// Un path relativo y uno absoluto comparten la transacción y la letra del disco
+	disk := newTestImage(t, pageSize)
+	relative, err := filepath.Rel(dir, disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := BeginTransaction(relative)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := BeginTransaction(disk); err == nil {
+		t.Fatalf("se iniciaron dos transacciones sobre %s usando %s", disk, relative)
+	}
+
+	letter, err := GetLetter(relative)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveLetter(disk)
+	if other, err := GetLetter(disk); err != nil || other != letter {
+		t.Fatalf("GetLetter(%q) = %q, %v, se esperaba %q", disk, other, err, letter)
+	}
+}
+
+// benchmarkImageSize es el tamaño de la imagen de los benchmarks, suficiente para que las lecturas no caigan siempre en la misma página
+const benchmarkImageSize = 4 * 1024 * 1024
+
+// benchmarkOffsets devuelve las posiciones que usa cada iteración, en saltos de 64 bytes como los inodos y bloques de una partición
+// Se repiten sobre las mismas 64 páginas, igual que un comando que vuelve a leer los bitmaps, la tabla de inodos y sus bloques
+func benchmarkOffsets() []int64 {
+	offsets := make([]int64, 4096)
+	for i := range offsets {
+		offsets[i] = int64(i*4160) % (64 * pageSize)
+	}
+	return offsets
+}
+
+// openBenchmarkImage crea la imagen y la abre para lectura y escritura
+func openBenchmarkImage(b *testing.B) (string, *os.File) {
+	b.Helper()
+	path := newTestImage(b, benchmarkImageSize)
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { file.Close() })
+	return path, file
+}
+
+func BenchmarkReadBytes(b *testing.B) {
+	offsets := benchmarkOffsets()
+	buffer := make([]byte, 64)
+
+	b.Run("sin caché", func(b *testing.B) {
+		_, file := openBenchmarkImage(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, offset := range offsets {
+				if err := ReadBytes(file, offset, buffer); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("con caché", func(b *testing.B) {
+		path, file := openBenchmarkImage(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Cada iteración es un comando de lectura, el caché empieza vacío
+			tx, err := BeginReadTransaction(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, offset := range offsets {
+				if err := ReadBytes(file, offset, buffer); err != nil {
+					b.Fatal(err)
+				}
+			}
+			tx.Rollback()
+		}
+	})
+}
+
+func BenchmarkWriteBytes(b *testing.B) {
+	offsets := benchmarkOffsets()
+	data := bytes.Repeat([]byte{0xAB}, 64)
+
+	b.Run("sin caché", func(b *testing.B) {
+		_, file := openBenchmarkImage(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, offset := range offsets {
+				if err := WriteBytes(file, offset, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("con caché", func(b *testing.B) {
+		path, file := openBenchmarkImage(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Cada iteración es un comando de escritura, incluye el registro y la escritura de las páginas sucias al confirmar
+			tx, err := BeginTransaction(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, offset := range offsets {
+				if err := WriteBytes(file, offset, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // ConvertToBytes convierte un tamaño y una unidad a bytes
@@ -35,8 +39,15 @@ var pathToLetter = make(map[string]string)
 // Índice para la siguiente letra disponible en el abecedario
 var nextLetterIndex = 0
 
+// letterMutex protege pathToLetter y nextLetterIndex, varios montajes pueden ejecutarse en paralelo
+var letterMutex sync.Mutex
+
 // GetLetter obtiene la letra asignada a un path
 func GetLetter(path string) (string, error) {
+	letterMutex.Lock()
+	defer letterMutex.Unlock()
+
+	path = DiskKey(path)
 	if _, exists := pathToLetter[path]; !exists {
 		if nextLetterIndex < len(alphabet) {
 			pathToLetter[path] = alphabet[nextLetterIndex]
@@ -51,17 +62,66 @@ func GetLetter(path string) (string, error) {
 
 // RemoveLetter elimina la letra asignada a un path
 func RemoveLetter(path string) {
-	delete(pathToLetter, path)
+	letterMutex.Lock()
+	defer letterMutex.Unlock()
+
+	delete(pathToLetter, DiskKey(path))
+}
+
+// LetterState contiene la asignación de letras a los discos, se guarda para conservarla entre reinicios
+type LetterState struct {
+	PathToLetter    map[string]string `json:"path_to_letter"`    // Letra asignada a cada path de disco
+	NextLetterIndex int               `json:"next_letter_index"` // Índice de la siguiente letra a asignar
+}
+
+// ExportLetters devuelve una copia de la asignación actual de letras
+func ExportLetters() LetterState {
+	letterMutex.Lock()
+	defer letterMutex.Unlock()
+
+	state := LetterState{PathToLetter: make(map[string]string, len(pathToLetter)), NextLetterIndex: nextLetterIndex}
+	for path, letter := range pathToLetter {
+		state.PathToLetter[path] = letter
+	}
+	return state
+}
+
+// RestoreLetters reemplaza la asignación de letras por la guardada en state
+func RestoreLetters(state LetterState) error {
+	if state.NextLetterIndex < 0 || state.NextLetterIndex > len(alphabet) {
+		return fmt.Errorf("índice de letra inválido: %d", state.NextLetterIndex)
+	}
+
+	restored := make(map[string]string, len(state.PathToLetter))
+	for path, letter := range state.PathToLetter {
+		index := slices.Index(alphabet, letter)
+		if index == -1 || index >= state.NextLetterIndex {
+			return fmt.Errorf("letra inválida '%s' para el disco %s", letter, path)
+		}
+		restored[DiskKey(path)] = letter
+	}
+
+	letterMutex.Lock()
+	defer letterMutex.Unlock()
+	pathToLetter = restored
+	nextLetterIndex = state.NextLetterIndex
+	return nil
 }
 
-// readFromFile lee datos desde un archivo binario en la posición especificada
+// ReadFromFile lee datos desde un archivo binario en la posición especificada
 func ReadFromFile(file *os.File, offset int64, data interface{}) error {
-	_, err := file.Seek(offset, 0)
+	size := binary.Size(data)
+	if size < 0 {
+		return fmt.Errorf("failed to read data from file: tipo de dato sin tamaño fijo %T", data)
+	}
+
+	buffer := make([]byte, size)
+	err := ReadBytes(file, offset, buffer)
 	if err != nil {
-		return fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+		return err
 	}
 
-	err = binary.Read(file, binary.LittleEndian, data)
+	err = binary.Read(bytes.NewReader(buffer), binary.LittleEndian, data)
 	if err != nil {
 		return fmt.Errorf("failed to read data from file: %w", err)
 	}
@@ -69,21 +129,62 @@ func ReadFromFile(file *os.File, offset int64, data interface{}) error {
 	return nil
 }
 
-// writeToFile escribe datos a un archivo binario en la posición especificada
+// WriteToFile escribe datos a un archivo binario en la posición especificada
 func WriteToFile(file *os.File, offset int64, data interface{}) error {
-	_, err := file.Seek(offset, 0)
+	var buffer bytes.Buffer
+	err := binary.Write(&buffer, binary.LittleEndian, data)
 	if err != nil {
-		return fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+		return fmt.Errorf("failed to write data to file: %w", err)
 	}
 
-	err = binary.Write(file, binary.LittleEndian, data)
-	if err != nil {
-		return fmt.Errorf("failed to write data to file: %w", err)
+	return WriteBytes(file, offset, buffer.Bytes())
+}
+
+// ReadBytes llena buffer con los bytes del archivo a partir de offset
+// Si el disco tiene una transacción activa se lee desde su caché, que incluye las escrituras pendientes
+func ReadBytes(file *os.File, offset int64, buffer []byte) error {
+	if tx := activeTransaction(file); tx != nil {
+		err := tx.read(file, offset, buffer)
+		if err != nil {
+			return fmt.Errorf("failed to read data from file at offset %d: %w", offset, err)
+		}
+		return nil
 	}
 
+	n, err := file.ReadAt(buffer, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && n == len(buffer)) {
+		return fmt.Errorf("failed to read data from file at offset %d: %w", offset, err)
+	}
 	return nil
 }
 
+// WriteBytes escribe data en el archivo a partir de offset
+// Si el disco tiene una transacción activa la escritura queda pendiente hasta confirmarla
+func WriteBytes(file *os.File, offset int64, data []byte) error {
+	if tx := activeTransaction(file); tx != nil {
+		err := tx.write(file, offset, data)
+		if err != nil {
+			return fmt.Errorf("failed to write data to file at offset %d: %w", offset, err)
+		}
+		return nil
+	}
+
+	_, err := file.WriteAt(data, offset)
+	if err != nil {
+		return fmt.Errorf("failed to write data to file at offset %d: %w", offset, err)
+	}
+	return nil
+}
+
+// ZeroFill escribe length ceros en el archivo a partir de offset
+// Si el disco tiene una transacción activa solo se registra el rango, sin reservar memoria para los ceros
+func ZeroFill(file *os.File, offset int64, length int64) error {
+	if tx := activeTransaction(file); tx != nil {
+		return tx.zero(offset, length)
+	}
+	return zeroFile(file, offset, length)
+}
+
 // createParentDirs crea las carpetas padre si no existen
 func CreateParentDirs(path string) error {
 	dir := filepath.Dir(path)
@@ -104,28 +205,11 @@ func GetFileNames(path string) (string, string) {
 	return dotFileName, outputImage
 }
 
-// First devuelve el primer elemento de un slice
-func First[T any](slice []T) (T, error) {
-	if len(slice) == 0 {
-		var zero T
-		return zero, errors.New("el slice está vacío")
-	}
-	return slice[0], nil
-}
-
-// RemoveElement elimina un elemento de un slice en el índice dado
-func RemoveElement[T any](slice []T, index int) []T {
-	if index < 0 || index >= len(slice) {
-		return slice // Índice fuera de rango, devolver el slice original
-	}
-	return append(slice[:index], slice[index+1:]...)
-}
-
-// splitStringIntoChunks divide una cadena en partes de tamaño chunkSize y las almacena en una lista
-func SplitStringIntoChunks(s string) []string {
+// SplitStringIntoChunks divide una cadena en partes de tamaño chunkSize y las almacena en una lista
+func SplitStringIntoChunks(s string, chunkSize int) []string {
 	var chunks []string
-	for i := 0; i < len(s); i += 64 {
-		end := i + 64
+	for i := 0; i < len(s); i += chunkSize {
+		end := i + chunkSize
 		if end > len(s) {
 			end = len(s)
 		}
@@ -133,25 +217,3 @@ func SplitStringIntoChunks(s string) []string {
 	}
 	return chunks
 }
-
-// GetParentDirectories obtiene las carpetas padres y el directorio de destino
-func GetParentDirectories(path string) ([]string, string) {
-	// Normalizar el path
-	path = filepath.Clean(path)
-
-	// Dividir el path en sus componentes
-	components := strings.Split(path, string(filepath.Separator))
-
-	// Lista para almacenar las rutas de las carpetas padres
-	var parentDirs []string
-
-	// Construir las rutas de las carpetas padres, excluyendo la última carpeta
-	for i := 1; i < len(components)-1; i++ {
-		parentDirs = append(parentDirs, components[i])
-	}
-
-	// La última carpeta es la carpeta de destino
-	destDir := components[len(components)-1]
-
-	return parentDirs, destDir
-}